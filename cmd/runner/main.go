@@ -67,6 +67,8 @@ func run(log *otelzap.Logger) error {
 		Interval          time.Duration `conf:"default:10s"`
 		MaxConcurrentJobs int           `conf:"default:100"`
 		MaxJobLockTime    time.Duration `conf:"default:1m"`
+		LockRenewInterval time.Duration `conf:"default:20s"`
+		Pools             []string
 	}{
 		Version: conf.Version{
 			Build: build,
@@ -140,6 +142,8 @@ func run(log *otelzap.Logger) error {
 		Interval:          cfg.Interval,
 		MaxConcurrentJobs: cfg.MaxConcurrentJobs,
 		JobLockDuration:   cfg.MaxJobLockTime,
+		LockRenewInterval: cfg.LockRenewInterval,
+		Pools:             cfg.Pools,
 	})
 
 	runnner.Start()
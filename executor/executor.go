@@ -3,17 +3,23 @@ package executor
 import (
 	"context"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"strings"
+	"time"
 
+	"github.com/GLCharge/distributed-scheduler/foundation/signature"
 	"github.com/GLCharge/distributed-scheduler/model"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
 type hTTPExecutor struct {
-	Client HttpClient
+	Client  HttpClient
+	tokens  *tokenCache  // the OAuth2 tokens of the runner
+	clients *clientCache // the clients of the jobs with their own TLS or proxy configuration
 }
 
 type aMQPExecutor struct{}
@@ -30,25 +36,94 @@ const (
 )
 
 func (he *hTTPExecutor) Execute(ctx context.Context, j *model.Job) error {
+	// Follow the redirects the job's redirect policy allows, and record them
+	ctx, redirects := withRedirectPolicy(ctx, j.HTTPJob.Redirects)
+
+	// Jobs with their own TLS or proxy configuration are sent with their own client
+	client, err := he.client(j.HTTPJob)
+	if err != nil {
+		return err
+	}
+
 	// Create the HTTP request
-	req, err := he.createHTTPRequest(ctx, j)
+	req, err := he.createHTTPRequest(ctx, j, client)
 	if err != nil {
 		return err
 	}
 
 	// Send the request and get the response
-	resp, err := he.Client.Do(req)
+	start := time.Now()
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
+
+	// An OAuth2 token can be revoked before it expires, so a rejected token is replaced and the request is sent once more
+	if resp.StatusCode == http.StatusUnauthorized && j.HTTPJob.Auth.Type == model.AuthTypeOAuth2ClientCredentials {
+		resp.Body.Close()
+
+		if req, err = he.renewOAuth2Token(ctx, req, j.HTTPJob.Auth, client); err != nil {
+			return err
+		}
+		redirects.hops = nil
+
+		start = time.Now()
+		if resp, err = client.Do(req); err != nil {
+			return err
+		}
+	}
 	defer resp.Body.Close()
 
+	latency := time.Since(start)
+
+	// Read as much of the body as is recorded with the execution and checked by the assertions
+	limit := j.HTTPJob.ResponseCapture.EffectiveBodyLimit()
+	if j.HTTPJob.Assertions.HasBodyAssertions() {
+		limit = model.MaxAssertedBodySize
+	}
+
+	body, truncated, bodyErr := he.readBody(resp.Body, limit)
+
+	// Record the response with the execution, to help debugging failed executions
+	j.Response = he.captureResponse(resp, body, truncated, j.HTTPJob.ResponseCapture)
+	j.Response.Redirects = redirects.hops
+
 	// Check if status code is one of the valid response codes
 	if !he.validResponseCode(resp.StatusCode, j.HTTPJob.ValidResponseCodes) {
-		return model.ErrInvalidResponseCode
+		return &model.HTTPStatusError{StatusCode: resp.StatusCode}
 	}
 
-	return nil
+	if bodyErr != nil && j.HTTPJob.Assertions.HasBodyAssertions() {
+		return fmt.Errorf("failed to read response body: %w", bodyErr)
+	}
+
+	// Check the response against the job's assertions
+	return j.HTTPJob.Assertions.Check(body, truncated, latency)
+}
+
+// jobClient is the client the requests of an HTTP job, and of its OAuth2 tokens, are sent with. Its key identifies
+// the job's TLS and proxy configuration, it is empty for the runner's client.
+type jobClient struct {
+	HttpClient
+	key string
+}
+
+// client returns the client the requests of the HTTP job are sent with.
+func (he *hTTPExecutor) client(httpJob *model.HTTPJob) (jobClient, error) {
+	if httpJob.TLS == nil && httpJob.Proxy == nil {
+		return jobClient{HttpClient: he.Client}, nil
+	}
+
+	if he.clients == nil {
+		return jobClient{}, errors.New("no client cache for the job's TLS or proxy configuration")
+	}
+
+	client, key, err := he.clients.Client(httpJob.TLS, httpJob.Proxy)
+	if err != nil {
+		return jobClient{}, err
+	}
+
+	return jobClient{HttpClient: client, key: key}, nil
 }
 
 func (he *hTTPExecutor) validResponseCode(code int, validCodes []int) bool {
@@ -67,24 +142,73 @@ func (he *hTTPExecutor) validResponseCode(code int, validCodes []int) bool {
 	return false
 }
 
-func (he *hTTPExecutor) createHTTPRequest(ctx context.Context, j *model.Job) (*http.Request, error) {
+// readBody reads up to limit bytes of the response body and reports whether the body is longer than that.
+func (he *hTTPExecutor) readBody(body io.Reader, limit int) ([]byte, bool, error) {
+	if limit == 0 {
+		return nil, false, nil
+	}
+
+	// read one more byte than the limit to tell whether the body is truncated
+	data, err := io.ReadAll(io.LimitReader(body, int64(limit)+1))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(data) > limit {
+		return data[:limit], true, nil
+	}
+
+	return data, false, nil
+}
+
+// captureResponse returns the status code, the selected headers and the start of the body of the response,
+// of which the given body was read. A body that couldn't be read, e.g. because the execution timed out
+// meanwhile, is left out.
+func (he *hTTPExecutor) captureResponse(resp *http.Response, body []byte, truncated bool, capture *model.ResponseCapture) *model.HTTPResponse {
+	response := &model.HTTPResponse{
+		StatusCode:    resp.StatusCode,
+		Headers:       capture.SelectHeaders(resp.Header),
+		BodyTruncated: truncated,
+	}
+
+	if limit := capture.EffectiveBodyLimit(); len(body) > limit {
+		body = body[:limit]
+		response.BodyTruncated = true
+	}
+
+	// the body is stored as text, so it must be valid UTF-8 without NUL characters
+	response.Body = strings.ReplaceAll(strings.ToValidUTF8(string(body), "\uFFFD"), "\x00", "")
+
+	return response
+}
+
+// createHTTPRequest creates the request of the HTTP job, an OAuth2 token is fetched with the job's client.
+func (he *hTTPExecutor) createHTTPRequest(ctx context.Context, j *model.Job, client jobClient) (*http.Request, error) {
+	// Render the URL, headers and body if they are templates
+	httpJob, err := j.HTTPJob.Render(j.TemplateData(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+
 	// Create the request body
-	body := he.createHTTPRequestBody(j.HTTPJob.Body.String)
+	body := he.createHTTPRequestBody(httpJob.Body.String)
 
 	// Create the request URL
-	url := he.createHTTPRequestURL(j.HTTPJob.URL)
+	url := he.createHTTPRequestURL(httpJob.URL)
 
 	// Create a new HTTP request
-	req, err := http.NewRequestWithContext(ctx, j.HTTPJob.Method, url, body)
+	req, err := http.NewRequestWithContext(ctx, httpJob.Method, url, body)
 	if err != nil {
 		return nil, err
 	}
 
 	// Set the headers
-	he.setHTTPRequestHeaders(req, j.HTTPJob.Headers)
+	he.setHTTPRequestHeaders(req, httpJob.Headers)
 
 	// Set the auth
-	he.setHTTPRequestAuth(req, j.HTTPJob.Auth)
+	if err := he.setHTTPRequestAuth(ctx, req, httpJob.Auth, client); err != nil {
+		return nil, err
+	}
 
 	return req, nil
 }
@@ -111,18 +235,68 @@ func (he *hTTPExecutor) setHTTPRequestHeaders(req *http.Request, headers map[str
 	}
 }
 
-func (he *hTTPExecutor) setHTTPRequestAuth(req *http.Request, auth model.Auth) {
+func (he *hTTPExecutor) setHTTPRequestAuth(ctx context.Context, req *http.Request, auth model.Auth, client jobClient) error {
 	switch auth.Type {
 	case model.AuthTypeBasic:
 		req.SetBasicAuth(auth.Username.String, auth.Password.String)
 	case model.AuthTypeBearer:
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth.BearerToken.String))
+	case model.AuthTypeOAuth2ClientCredentials:
+		if he.tokens == nil {
+			return fmt.Errorf("%w: no token cache", errTokenNotIssued)
+		}
+
+		token, err := he.tokens.Token(ctx, client, auth)
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	case model.AuthTypeHMAC:
+		// the request is signed last, after its headers and body are set
+		algorithm := signature.DefaultAlgorithm
+		if auth.SignatureAlgorithm.Valid {
+			algorithm = signature.Algorithm(auth.SignatureAlgorithm.String)
+		}
+
+		if err := signature.Sign(req, algorithm, []byte(auth.SigningSecret.String), auth.SignatureHeader.String, time.Now()); err != nil {
+			return fmt.Errorf("failed to sign request: %w", err)
+		}
 	}
+
+	return nil
+}
+
+// renewOAuth2Token drops the OAuth2 token the request was rejected with and returns a copy of the request
+// with a new token.
+func (he *hTTPExecutor) renewOAuth2Token(ctx context.Context, req *http.Request, auth model.Auth, client jobClient) (*http.Request, error) {
+	he.tokens.Invalidate(client, auth, strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "))
+
+	retry := req.Clone(ctx)
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retry.Body = body
+	}
+
+	if err := he.setHTTPRequestAuth(ctx, retry, auth, client); err != nil {
+		return nil, err
+	}
+
+	return retry, nil
 }
 
 func (ae *aMQPExecutor) Execute(ctx context.Context, j *model.Job) error {
+	// Render the routing key, headers and body if they are templates
+	amqpJob, err := j.AMQPJob.Render(j.TemplateData(time.Now()))
+	if err != nil {
+		return err
+	}
+
 	// Create a new AMQP connection
-	conn, err := amqp.Dial(j.AMQPJob.Connection)
+	conn, err := ae.dial(ctx, amqpJob.Connection)
 	if err != nil {
 		return fmt.Errorf("failed to connect to AMQP: %w", err)
 	}
@@ -135,33 +309,21 @@ func (ae *aMQPExecutor) Execute(ctx context.Context, j *model.Job) error {
 	}
 	defer ch.Close()
 
-	var body []byte
-
-	if j.AMQPJob.BodyEncoding != nil {
-		switch *j.AMQPJob.BodyEncoding {
-		case model.BodyEncodingBase64:
-			body, err = base64.StdEncoding.DecodeString(j.AMQPJob.Body)
-			if err != nil {
-				return fmt.Errorf("failed to decode body: %w", err)
-			}
-		default:
-			return model.ErrInvalidBodyEncoding
-		}
-
-	} else {
-		body = []byte(j.AMQPJob.Body)
+	body, err := decodeBody(amqpJob.Body, amqpJob.BodyEncoding)
+	if err != nil {
+		return err
 	}
 
 	// Publish a message to the exchange
 	err = ch.PublishWithContext(
 		ctx,
-		j.AMQPJob.Exchange,   // exchange
-		j.AMQPJob.RoutingKey, // routing key
-		false,                // mandatory
-		false,                // immediate
+		amqpJob.Exchange,   // exchange
+		amqpJob.RoutingKey, // routing key
+		false,              // mandatory
+		false,              // immediate
 		amqp.Publishing{
-			ContentType: j.AMQPJob.ContentType,
-			Headers:     j.AMQPJob.Headers,
+			ContentType: amqpJob.ContentType,
+			Headers:     amqpJob.Headers,
 			Body:        body,
 		},
 	)
@@ -171,3 +333,57 @@ func (ae *aMQPExecutor) Execute(ctx context.Context, j *model.Job) error {
 
 	return nil
 }
+
+// decodeBody returns the body of a message, decoded if it has an encoding.
+func decodeBody(body string, encoding *model.BodyEncoding) ([]byte, error) {
+	if encoding == nil {
+		return []byte(body), nil
+	}
+
+	switch *encoding {
+	case model.BodyEncodingBase64:
+		decoded, err := base64.StdEncoding.DecodeString(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode body: %w", err)
+		}
+		return decoded, nil
+	default:
+		return nil, model.ErrInvalidBodyEncoding
+	}
+}
+
+// AMQP connection defaults, the same as used by amqp.Dial
+const (
+	amqpConnectionTimeout = 30 * time.Second
+	amqpHeartbeat         = 10 * time.Second
+	amqpLocale            = "en_US"
+)
+
+// dial opens an AMQP connection. Unlike amqp.Dial, connecting and the handshake
+// are bounded by the context, so they can't outlive the job's timeout.
+func (ae *aMQPExecutor) dial(ctx context.Context, url string) (*amqp.Connection, error) {
+	return amqp.DialConfig(url, amqp.Config{
+		Heartbeat: amqpHeartbeat,
+		Locale:    amqpLocale,
+		Dial: func(network, addr string) (net.Conn, error) {
+			dialer := &net.Dialer{Timeout: amqpConnectionTimeout}
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+
+			// the deadline covers the handshake and is cleared once the connection is established
+			deadline := time.Now().Add(amqpConnectionTimeout)
+			if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+				deadline = ctxDeadline
+			}
+
+			if err := conn.SetDeadline(deadline); err != nil {
+				conn.Close()
+				return nil, err
+			}
+
+			return conn, nil
+		},
+	})
+}
@@ -3,12 +3,18 @@ package executor
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"github.com/GLCharge/distributed-scheduler/foundation/signature"
 	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/google/uuid"
 	"gopkg.in/guregu/null.v4"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -119,7 +125,7 @@ func TestHTTPExecutor_createHTTPRequest(t *testing.T) {
 	}
 
 	httpExecutor := &hTTPExecutor{}
-	req, err := httpExecutor.createHTTPRequest(ctx, j)
+	req, err := httpExecutor.createHTTPRequest(ctx, j, jobClient{})
 
 	assert.Nil(t, err)
 	assert.Equal(t, j.HTTPJob.Method, req.Method)
@@ -127,6 +133,150 @@ func TestHTTPExecutor_createHTTPRequest(t *testing.T) {
 	assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte(j.HTTPJob.Auth.Username.String+":"+j.HTTPJob.Auth.Password.String)), req.Header.Get("Authorization"))
 }
 
+func TestHTTPExecutor_createHTTPRequestTemplate(t *testing.T) {
+	ctx := context.Background()
+	j := &model.Job{
+		ID:          uuid.New(),
+		RunID:       uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		ExecutionID: uuid.New(),
+		Attempt:     1,
+		ScheduledAt: null.TimeFrom(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)),
+		HTTPJob: &model.HTTPJob{
+			Method: "POST",
+			URL:    "www.example.com/jobs/{{ .JobID }}",
+			Headers: map[string]string{
+				"Idempotency-Key": "{{ .ExecutionID }}",
+			},
+			Body:     null.StringFrom(`{"since": "{{ rfc3339 .ScheduledAt }}"}`),
+			Auth:     model.Auth{Type: model.AuthTypeNone},
+			Template: true,
+		},
+	}
+
+	httpExecutor := &hTTPExecutor{}
+	req, err := httpExecutor.createHTTPRequest(ctx, j, jobClient{})
+	assert.Nil(t, err)
+
+	body, err := io.ReadAll(req.Body)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "https://www.example.com/jobs/"+j.ID.String(), req.URL.String())
+	assert.Equal(t, j.ExecutionID.String(), req.Header.Get("Idempotency-Key"))
+	assert.Equal(t, `{"since": "2023-06-01T12:00:00Z"}`, string(body))
+}
+
+func TestHTTPExecutor_captureResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", "120")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-Api-Key", "secret")
+		w.Header().Set("X-Internal", "not recorded")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"ok": false, "error": "maintenance"}`))
+	}))
+	defer server.Close()
+
+	bodyLimit := 12
+	j := &model.Job{
+		HTTPJob: &model.HTTPJob{
+			Method: "GET",
+			URL:    server.URL,
+			Auth:   model.Auth{Type: model.AuthTypeNone},
+			ResponseCapture: &model.ResponseCapture{
+				Headers:   []string{"x-ratelimit-remaining", "X-Api-Key"},
+				BodyLimit: &bodyLimit,
+			},
+		},
+	}
+
+	httpExecutor := &hTTPExecutor{Client: server.Client()}
+	err := httpExecutor.Execute(context.Background(), j)
+
+	var statusErr *model.HTTPStatusError
+	assert.ErrorAs(t, err, &statusErr)
+
+	assert.Equal(t, &model.HTTPResponse{
+		StatusCode: http.StatusServiceUnavailable,
+		Headers: map[string]string{
+			"Content-Type":          "application/json",
+			"Content-Length":        "37",
+			"Retry-After":           "120",
+			"X-Ratelimit-Remaining": "0",
+			"X-Api-Key":             model.RedactedHeaderValue,
+		},
+		Body:          `{"ok": false`,
+		BodyTruncated: true,
+	}, j.Response)
+}
+
+func TestHTTPExecutor_assertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok": false}`))
+	}))
+	defer server.Close()
+
+	j := &model.Job{
+		HTTPJob: &model.HTTPJob{
+			Method: "GET",
+			URL:    server.URL,
+			Auth:   model.Auth{Type: model.AuthTypeNone},
+			Assertions: &model.ResponseAssertions{
+				JSONPath: []model.JSONPathAssertion{{Path: "$.ok", Equals: json.RawMessage(`true`)}},
+			},
+		},
+	}
+
+	httpExecutor := &hTTPExecutor{Client: server.Client()}
+
+	// a response with a valid status code fails the assertion
+	err := httpExecutor.Execute(context.Background(), j)
+	assert.EqualError(t, err, "assertion failed: json_path $.ok: expected true, got false")
+	assert.Equal(t, `{"ok": false}`, j.Response.Body)
+
+	j.HTTPJob.Assertions.JSONPath[0].Equals = json.RawMessage(`false`)
+	assert.Nil(t, httpExecutor.Execute(context.Background(), j))
+}
+
+func TestHTTPExecutor_hmacSignature(t *testing.T) {
+	verifier := &signature.Verifier{Secret: []byte("secret"), Header: "X-Signature"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := verifier.Verify(r); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// the body can still be read after the signature is verified
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}))
+	defer server.Close()
+
+	j := &model.Job{
+		HTTPJob: &model.HTTPJob{
+			Method: "POST",
+			URL:    server.URL + "/hooks?source=scheduler",
+			Body:   null.StringFrom(`{"hello": "world"}`),
+			Auth: model.Auth{
+				Type:               model.AuthTypeHMAC,
+				SigningSecret:      null.StringFrom("secret"),
+				SignatureAlgorithm: null.StringFrom("sha512"),
+				SignatureHeader:    null.StringFrom("X-Signature"),
+			},
+		},
+	}
+
+	httpExecutor := &hTTPExecutor{Client: server.Client()}
+
+	assert.Nil(t, httpExecutor.Execute(context.Background(), j))
+	assert.Equal(t, `{"hello": "world"}`, j.Response.Body)
+
+	// a request signed with another secret is rejected
+	j.HTTPJob.Auth.SigningSecret = null.StringFrom("other")
+	assert.Equal(t, &model.HTTPStatusError{StatusCode: http.StatusUnauthorized}, httpExecutor.Execute(context.Background(), j))
+}
+
 func TestHTTPExecutor_validResponseCode(t *testing.T) {
 	httpExecutor := &hTTPExecutor{}
 
@@ -139,3 +289,29 @@ func TestHTTPExecutor_validResponseCode(t *testing.T) {
 	assert.True(t, httpExecutor.validResponseCode(http.StatusOK, validResponseCodes))
 	assert.False(t, httpExecutor.validResponseCode(http.StatusInternalServerError, validResponseCodes))
 }
+
+func TestAMQPExecutor_dialTimeout(t *testing.T) {
+	// a broker that accepts connections but never completes the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = (&aMQPExecutor{}).dial(ctx, "amqp://guest:guest@"+listener.Addr().String()+"/")
+
+	assert.NotNil(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -10,16 +10,31 @@ type Factory interface {
 }
 
 type factory struct {
-	client HttpClient
+	client       HttpClient
+	tokens       *tokenCache
+	clients      *clientCache
+	mqttClients  *mqttClientCache
+	redisClients *redisClientCache
 }
 
+// NewFactory returns a factory whose HTTP executors send their requests with the client. The jobs with
+// their own TLS or proxy configuration are sent with copies of the client that have their own transport.
+// The redirects are followed as the jobs' redirect policies allow. The connections of the MQTT and Redis jobs
+// are kept open and shared by their executors.
 func NewFactory(client HttpClient) Factory {
+	jobClient := withCheckRedirect(client)
+
 	return &factory{
-		client: client,
+		client:       jobClient,
+		tokens:       newTokenCache(),
+		clients:      newClientCache(jobClient),
+		mqttClients:  newMQTTClientCache(),
+		redisClients: newRedisClientCache(),
 	}
 }
 
-// Option is a function that modifies an executor before it is returned (e.g. WithRetry)
+// Option is a function that modifies an executor before it is returned, e.g. by wrapping it. Failed executions
+// aren't retried by a wrapping executor: the job service stores the retry, see model.Job.ShouldRetry.
 type Option func(executor model.Executor) model.Executor
 
 func (f *factory) NewExecutor(job *model.Job, options ...Option) (model.Executor, error) {
@@ -27,9 +42,19 @@ func (f *factory) NewExecutor(job *model.Job, options ...Option) (model.Executor
 	var executor model.Executor
 	switch job.Type {
 	case model.JobTypeHTTP:
-		executor = &hTTPExecutor{Client: f.client}
+		executor = &hTTPExecutor{Client: f.client, tokens: f.tokens, clients: f.clients}
 	case model.JobTypeAMQP:
 		executor = &aMQPExecutor{}
+	case model.JobTypeGRPC:
+		executor = &gRPCExecutor{}
+	case model.JobTypeKafka:
+		executor = &kafkaExecutor{}
+	case model.JobTypeMQTT:
+		executor = &mQTTExecutor{clients: f.mqttClients}
+	case model.JobTypeNATS:
+		executor = &nATSExecutor{}
+	case model.JobTypeRedis:
+		executor = &redisExecutor{clients: f.redisClients}
 	default:
 		return nil, fmt.Errorf("unknown job type: %v", job.Type)
 	}
@@ -27,13 +27,47 @@ func TestNewExecutor(t *testing.T) {
 	assert.Nil(t, err)
 	assert.IsType(t, &aMQPExecutor{}, executor)
 
+	j.Type = model.JobTypeGRPC
+	executor, err = factory.NewExecutor(j)
+	assert.Nil(t, err)
+	assert.IsType(t, &gRPCExecutor{}, executor)
+
+	j.Type = model.JobTypeKafka
+	executor, err = factory.NewExecutor(j)
+	assert.Nil(t, err)
+	assert.IsType(t, &kafkaExecutor{}, executor)
+
+	j.Type = model.JobTypeMQTT
+	executor, err = factory.NewExecutor(j)
+	assert.Nil(t, err)
+	assert.IsType(t, &mQTTExecutor{}, executor)
+
+	j.Type = model.JobTypeNATS
+	executor, err = factory.NewExecutor(j)
+	assert.Nil(t, err)
+	assert.IsType(t, &nATSExecutor{}, executor)
+
+	j.Type = model.JobTypeRedis
+	executor, err = factory.NewExecutor(j)
+	assert.Nil(t, err)
+	assert.IsType(t, &redisExecutor{}, executor)
+
 	j.Type = "unknown"
 	executor, err = factory.NewExecutor(j)
 	assert.NotNil(t, err)
 	assert.Nil(t, executor)
 
 	j.Type = model.JobTypeHTTP
-	executor, err = factory.NewExecutor(j, WithRetry)
+	executor, err = factory.NewExecutor(j, withWrapper)
 	assert.Nil(t, err)
-	assert.IsType(t, &retryExecutor{}, executor)
+	assert.IsType(t, &wrapperExecutor{}, executor)
+}
+
+// wrapperExecutor is used to check that options are applied to the created executor
+type wrapperExecutor struct {
+	model.Executor
+}
+
+func withWrapper(executor model.Executor) model.Executor {
+	return &wrapperExecutor{Executor: executor}
 }
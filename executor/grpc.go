@@ -0,0 +1,167 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+type gRPCExecutor struct{}
+
+func (ge *gRPCExecutor) Execute(ctx context.Context, j *model.Job) error {
+	grpcJob := j.GRPCJob
+
+	creds, err := ge.credentials(grpcJob)
+	if err != nil {
+		return err
+	}
+
+	// Create a new gRPC connection, it is established by the first call
+	conn, err := grpc.DialContext(ctx, grpcJob.Target, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return fmt.Errorf("failed to connect to gRPC server: %w", err)
+	}
+	defer conn.Close()
+
+	// The metadata is sent with the reflection requests too, the server may require it for them as well
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(grpcJob.Metadata))
+
+	method, err := ge.method(ctx, conn, grpcJob)
+	if err != nil {
+		return err
+	}
+
+	request, err := grpcJob.RequestMessage(method)
+	if err != nil {
+		return fmt.Errorf("invalid request message: %w", err)
+	}
+
+	response := dynamicpb.NewMessage(method.Output())
+
+	return conn.Invoke(ctx, grpcJob.FullMethod(), request, response)
+}
+
+// credentials returns the transport credentials of the job's connection, TLS unless the job is plaintext.
+func (ge *gRPCExecutor) credentials(grpcJob *model.GRPCJob) (credentials.TransportCredentials, error) {
+	if grpcJob.Plaintext {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig, err := loadTLSConfig(grpcJob.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// method returns the descriptor of the job's method, from the job's descriptor set or with server reflection.
+func (ge *gRPCExecutor) method(ctx context.Context, conn *grpc.ClientConn, grpcJob *model.GRPCJob) (protoreflect.MethodDescriptor, error) {
+	if grpcJob.DescriptorSet != nil {
+		method, err := grpcJob.MethodDescriptor()
+		if err != nil {
+			return nil, fmt.Errorf("invalid descriptor set: %w", err)
+		}
+
+		return method, nil
+	}
+
+	files, err := ge.reflectFiles(ctx, conn, grpcJob)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve method with server reflection: %w", err)
+	}
+
+	return grpcJob.FindMethod(files)
+}
+
+// reflectFiles fetches the file that defines the job's service, and the files it depends on, from the server's
+// reflection service. The dependencies the server doesn't send, e.g. the well-known types, are taken from the
+// files linked into the runner.
+func (ge *gRPCExecutor) reflectFiles(ctx context.Context, conn *grpc.ClientConn, grpcJob *model.GRPCJob) (*protoregistry.Files, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.CloseSend()
+
+	serviceName, _ := grpcJob.ServiceAndMethod()
+
+	files := make(map[string]*descriptorpb.FileDescriptorProto)
+	request := &reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: string(serviceName)},
+	}
+
+	for request != nil {
+		if err := stream.Send(request); err != nil {
+			return nil, err
+		}
+
+		response, err := stream.Recv()
+		if err != nil {
+			return nil, err
+		}
+
+		if errorResponse := response.GetErrorResponse(); errorResponse != nil {
+			return nil, fmt.Errorf("%s", errorResponse.GetErrorMessage())
+		}
+
+		for _, data := range response.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(data, file); err != nil {
+				return nil, err
+			}
+			files[file.GetName()] = file
+		}
+
+		// the dependencies that are linked into the runner can have dependencies of their own
+		request = nil
+		for request == nil {
+			missing := missingDependencies(files)
+			if len(missing) == 0 {
+				break
+			}
+
+			if linked, err := protoregistry.GlobalFiles.FindFileByPath(missing[0]); err == nil {
+				files[missing[0]] = protodesc.ToFileDescriptorProto(linked)
+				continue
+			}
+
+			request = &reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: missing[0]},
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+
+	return protodesc.NewFiles(set)
+}
+
+// missingDependencies returns the files the files depend on that aren't among them.
+func missingDependencies(files map[string]*descriptorpb.FileDescriptorProto) []string {
+	var missing []string
+	for _, file := range files {
+		for _, dependency := range file.GetDependency() {
+			if _, ok := files[dependency]; !ok {
+				missing = append(missing, dependency)
+			}
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,179 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// testGRPCServer is an in-process gRPC server with the health service and server reflection. It records the
+// metadata of the health checks.
+type testGRPCServer struct {
+	addr     string
+	metadata chan metadata.MD
+}
+
+func newTestGRPCServer(t *testing.T, options ...grpc.ServerOption) *testGRPCServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ts := &testGRPCServer{addr: listener.Addr().String(), metadata: make(chan metadata.MD, 1)}
+
+	options = append(options, grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		ts.metadata <- md
+		return handler(ctx, req)
+	}))
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus("billing", healthpb.HealthCheckResponse_SERVING)
+
+	server := grpc.NewServer(options...)
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	return ts
+}
+
+// healthDescriptorSet returns the FileDescriptorSet of the health service, as protoc would write it.
+func healthDescriptorSet(t *testing.T) []byte {
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto)},
+	}
+
+	data, err := proto.Marshal(set)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestGRPCExecutor_Execute(t *testing.T) {
+	server := newTestGRPCServer(t)
+
+	tests := []struct {
+		name     string
+		grpcJob  *model.GRPCJob
+		wantCode codes.Code
+	}{
+		{
+			name: "server reflection",
+			grpcJob: &model.GRPCJob{
+				Method:  "/grpc.health.v1.Health/Check",
+				Message: []byte(`{"service": "billing"}`),
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "descriptor set",
+			grpcJob: &model.GRPCJob{
+				Method:        "grpc.health.v1.Health/Check",
+				Message:       []byte(`{"service": "billing"}`),
+				DescriptorSet: healthDescriptorSet(t),
+			},
+			wantCode: codes.OK,
+		},
+		{
+			name: "error status",
+			grpcJob: &model.GRPCJob{
+				Method:  "/grpc.health.v1.Health/Check",
+				Message: []byte(`{"service": "invoices"}`),
+			},
+			wantCode: codes.NotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.grpcJob.Target = server.addr
+			tt.grpcJob.Plaintext = true
+			tt.grpcJob.Metadata = map[string]string{"authorization": "Bearer s3cr3t"}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err := (&gRPCExecutor{}).Execute(ctx, &model.Job{Type: model.JobTypeGRPC, GRPCJob: tt.grpcJob})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+
+			md := <-server.metadata
+			assert.Equal(t, []string{"Bearer s3cr3t"}, md.Get("authorization"))
+		})
+	}
+}
+
+func TestGRPCExecutor_unknownMethod(t *testing.T) {
+	server := newTestGRPCServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job := &model.Job{
+		Type: model.JobTypeGRPC,
+		GRPCJob: &model.GRPCJob{
+			Target:    server.addr,
+			Method:    "/billing.v1.Invoices/Close",
+			Plaintext: true,
+		},
+	}
+
+	err := (&gRPCExecutor{}).Execute(ctx, job)
+	assert.ErrorContains(t, err, "failed to resolve method with server reflection")
+}
+
+func TestGRPCExecutor_TLS(t *testing.T) {
+	ca := newTestCA(t)
+
+	certPEM, keyPEM := ca.issue(t, "billing.internal")
+	certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+
+	server := newTestGRPCServer(t, grpc.Creds(credentials.NewServerTLSFromCert(&certificate)))
+
+	newJob := func(tlsConfig *model.TLSConfig) *model.Job {
+		return &model.Job{
+			Type: model.JobTypeGRPC,
+			GRPCJob: &model.GRPCJob{
+				Target:        server.addr,
+				Method:        "/grpc.health.v1.Health/Check",
+				Message:       []byte(`{"service": "billing"}`),
+				DescriptorSet: healthDescriptorSet(t),
+				TLS:           tlsConfig,
+			},
+		}
+	}
+
+	t.Run("trusted CA", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := (&gRPCExecutor{}).Execute(ctx, newJob(&model.TLSConfig{CACert: ca.pem, ServerName: "billing.internal"}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown CA", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := (&gRPCExecutor{}).Execute(ctx, newJob(&model.TLSConfig{ServerName: "billing.internal"}))
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+	})
+}
@@ -0,0 +1,105 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// kafkaDialTimeout bounds connecting to a broker, including the TLS and SASL handshakes.
+const kafkaDialTimeout = 10 * time.Second
+
+type kafkaExecutor struct{}
+
+func (ke *kafkaExecutor) Execute(ctx context.Context, j *model.Job) error {
+	kafkaJob := j.KafkaJob
+
+	body, err := decodeBody(kafkaJob.Body, kafkaJob.BodyEncoding)
+	if err != nil {
+		return err
+	}
+
+	transport, err := ke.transport(kafkaJob)
+	if err != nil {
+		return err
+	}
+	defer transport.CloseIdleConnections()
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(kafkaJob.Brokers...),
+		Topic:        kafkaJob.Topic,
+		Balancer:     &kafka.Murmur2Balancer{}, // the partitioner of the Java client
+		RequiredAcks: ke.requiredAcks(kafkaJob.Acks),
+		MaxAttempts:  1, // failed executions are retried with the job's retry policy
+		BatchSize:    1, // the record is sent right away instead of waiting for a batch
+		Transport:    transport,
+	}
+	defer writer.Close()
+
+	message := kafka.Message{Value: body}
+	if kafkaJob.Key != "" {
+		message.Key = []byte(kafkaJob.Key)
+	}
+	for key, value := range kafkaJob.Headers {
+		message.Headers = append(message.Headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+
+	if err := writer.WriteMessages(ctx, message); err != nil {
+		return fmt.Errorf("failed to produce record: %w", err)
+	}
+
+	return nil
+}
+
+// transport returns the transport the job's record is sent with, with its TLS and SASL configuration.
+func (ke *kafkaExecutor) transport(kafkaJob *model.KafkaJob) (*kafka.Transport, error) {
+	transport := &kafka.Transport{DialTimeout: kafkaDialTimeout}
+
+	if kafkaJob.TLS != nil {
+		tlsConfig, err := loadTLSConfig(kafkaJob.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLS = tlsConfig
+	}
+
+	if kafkaJob.SASL != nil {
+		mechanism, err := ke.saslMechanism(kafkaJob.SASL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure SASL: %w", err)
+		}
+		transport.SASL = mechanism
+	}
+
+	return transport, nil
+}
+
+func (ke *kafkaExecutor) saslMechanism(config *model.KafkaSASL) (sasl.Mechanism, error) {
+	switch config.Mechanism {
+	case model.KafkaSASLPlain:
+		return plain.Mechanism{Username: config.Username, Password: config.Password}, nil
+	case model.KafkaSASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, config.Username, config.Password)
+	case model.KafkaSASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, config.Username, config.Password)
+	default:
+		return nil, model.ErrInvalidKafkaSASL
+	}
+}
+
+func (ke *kafkaExecutor) requiredAcks(acks model.KafkaAcks) kafka.RequiredAcks {
+	switch acks {
+	case model.KafkaAcksNone:
+		return kafka.RequireNone
+	case model.KafkaAcksLeader:
+		return kafka.RequireOne
+	default:
+		return kafka.RequireAll
+	}
+}
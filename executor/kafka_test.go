@@ -0,0 +1,382 @@
+package executor
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/segmentio/kafka-go/protocol"
+	"github.com/segmentio/kafka-go/protocol/apiversions"
+	"github.com/segmentio/kafka-go/protocol/metadata"
+	"github.com/segmentio/kafka-go/protocol/produce"
+	"github.com/segmentio/kafka-go/protocol/saslauthenticate"
+	"github.com/segmentio/kafka-go/protocol/saslhandshake"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Kafka error codes the test broker answers with.
+const (
+	kafkaUnknownTopicOrPartition  = 3
+	kafkaSASLAuthenticationFailed = 58
+)
+
+// testKafkaBroker is an in-process stand-in for a Kafka cluster with a single broker. It speaks enough of the
+// Kafka protocol for a producer: it negotiates the API versions, authenticates with SASL PLAIN, answers the
+// metadata requests for its topic and records the records it receives.
+type testKafkaBroker struct {
+	addr       *net.TCPAddr
+	topic      string
+	partitions int32
+	users      map[string]string // the PLAIN credentials the broker requires, none if nil
+	records    chan testKafkaRecord
+}
+
+// testKafkaRecord is a record the test broker received, with the acks of its produce request.
+type testKafkaRecord struct {
+	acks    int16
+	key     []byte
+	value   []byte
+	headers map[string]string
+}
+
+// newTestKafkaBroker starts a broker with a topic of 3 partitions. If tlsConfig isn't nil, the broker only
+// accepts TLS connections.
+func newTestKafkaBroker(t *testing.T, topic string, users map[string]string, tlsConfig *tls.Config) *testKafkaBroker {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	broker := &testKafkaBroker{
+		addr:       listener.Addr().(*net.TCPAddr),
+		topic:      topic,
+		partitions: 3,
+		users:      users,
+		records:    make(chan testKafkaRecord, 10),
+	}
+
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go broker.serve(conn)
+		}
+	}()
+
+	return broker
+}
+
+// serve answers the requests of a connection until it is closed, or a request isn't allowed.
+func (b *testKafkaBroker) serve(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	authenticated := b.users == nil
+
+	for {
+		version, correlationID, _, request, err := protocol.ReadRequest(reader)
+		if err != nil {
+			return
+		}
+
+		var response protocol.Message
+		switch request := request.(type) {
+		case *apiversions.Request:
+			response = b.apiVersions()
+		case *saslhandshake.Request:
+			response = &saslhandshake.Response{Mechanisms: []string{"PLAIN"}}
+		case *saslauthenticate.Request:
+			authenticated = b.authenticate(request.AuthBytes)
+			if authenticated {
+				response = &saslauthenticate.Response{}
+			} else {
+				response = &saslauthenticate.Response{ErrorCode: kafkaSASLAuthenticationFailed, ErrorMessage: "invalid credentials"}
+			}
+		case *metadata.Request:
+			if !authenticated {
+				return
+			}
+			response = b.metadata(request)
+		case *produce.Request:
+			if !authenticated {
+				return
+			}
+			response = b.produce(request)
+			if !request.HasResponse() {
+				continue
+			}
+		default:
+			return
+		}
+
+		if err := protocol.WriteResponse(conn, version, correlationID, response); err != nil {
+			return
+		}
+	}
+}
+
+func (b *testKafkaBroker) apiVersions() *apiversions.Response {
+	response := &apiversions.Response{}
+	for _, key := range []protocol.ApiKey{protocol.ApiVersions, protocol.Metadata, protocol.Produce, protocol.SaslAuthenticate} {
+		response.ApiKeys = append(response.ApiKeys, apiversions.ApiKeyResponse{
+			ApiKey:     int16(key),
+			MinVersion: key.MinVersion(),
+			MaxVersion: key.MaxVersion(),
+		})
+	}
+
+	// the SASL messages are only framed like the other messages from version 1 of the handshake
+	response.ApiKeys = append(response.ApiKeys, apiversions.ApiKeyResponse{ApiKey: int16(protocol.SaslHandshake), MinVersion: 1, MaxVersion: 1})
+
+	return response
+}
+
+// authenticate checks the SASL PLAIN message: the authorization identity, the username and the password, separated by NUL.
+func (b *testKafkaBroker) authenticate(message []byte) bool {
+	parts := bytes.Split(message, []byte{0})
+	if len(parts) != 3 {
+		return false
+	}
+
+	password, ok := b.users[string(parts[1])]
+	return ok && password == string(parts[2])
+}
+
+func (b *testKafkaBroker) metadata(request *metadata.Request) *metadata.Response {
+	response := &metadata.Response{
+		Brokers:      []metadata.ResponseBroker{{NodeID: 1, Host: b.addr.IP.String(), Port: int32(b.addr.Port)}},
+		ControllerID: 1,
+	}
+
+	names := request.TopicNames
+	if names == nil {
+		names = []string{b.topic}
+	}
+
+	for _, name := range names {
+		topic := metadata.ResponseTopic{Name: name}
+		if name != b.topic {
+			topic.ErrorCode = kafkaUnknownTopicOrPartition
+			response.Topics = append(response.Topics, topic)
+			continue
+		}
+
+		for partition := int32(0); partition < b.partitions; partition++ {
+			topic.Partitions = append(topic.Partitions, metadata.ResponsePartition{
+				PartitionIndex: partition,
+				LeaderID:       1,
+				ReplicaNodes:   []int32{1},
+				IsrNodes:       []int32{1},
+			})
+		}
+		response.Topics = append(response.Topics, topic)
+	}
+
+	return response
+}
+
+func (b *testKafkaBroker) produce(request *produce.Request) *produce.Response {
+	response := &produce.Response{}
+
+	for _, topic := range request.Topics {
+		responseTopic := produce.ResponseTopic{Topic: topic.Topic}
+
+		for _, partition := range topic.Partitions {
+			responsePartition := produce.ResponsePartition{Partition: partition.Partition}
+			if topic.Topic != b.topic {
+				responsePartition.ErrorCode = kafkaUnknownTopicOrPartition
+			} else {
+				b.receive(request.Acks, partition.RecordSet.Records)
+			}
+			responseTopic.Partitions = append(responseTopic.Partitions, responsePartition)
+		}
+
+		response.Topics = append(response.Topics, responseTopic)
+	}
+
+	return response
+}
+
+func (b *testKafkaBroker) receive(acks int16, records protocol.RecordReader) {
+	for {
+		record, err := records.ReadRecord()
+		if err != nil {
+			return
+		}
+
+		received := testKafkaRecord{acks: acks, headers: make(map[string]string)}
+		received.key, _ = protocol.ReadAll(record.Key)
+		received.value, _ = protocol.ReadAll(record.Value)
+		for _, header := range record.Headers {
+			received.headers[header.Key] = string(header.Value)
+		}
+
+		b.records <- received
+	}
+}
+
+// record returns the next record the broker receives.
+func (b *testKafkaBroker) record(t *testing.T) testKafkaRecord {
+	select {
+	case record := <-b.records:
+		return record
+	case <-time.After(5 * time.Second):
+		t.Fatal("no record received")
+		return testKafkaRecord{}
+	}
+}
+
+func TestKafkaExecutor_Execute(t *testing.T) {
+	broker := newTestKafkaBroker(t, "billing.invoices", nil, nil)
+
+	base64Encoding := model.BodyEncodingBase64
+
+	tests := []struct {
+		name     string
+		kafkaJob model.KafkaJob
+		want     testKafkaRecord
+	}{
+		{
+			name: "record with key and headers",
+			kafkaJob: model.KafkaJob{
+				Key:     "customer-42",
+				Headers: map[string]string{"source": "scheduler"},
+				Body:    `{"period": "2024-01"}`,
+			},
+			want: testKafkaRecord{acks: -1, key: []byte("customer-42"), value: []byte(`{"period": "2024-01"}`), headers: map[string]string{"source": "scheduler"}},
+		},
+		{
+			name: "base64 encoded body",
+			kafkaJob: model.KafkaJob{
+				Body:         base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0x10}),
+				BodyEncoding: &base64Encoding,
+				Acks:         model.KafkaAcksLeader,
+			},
+			want: testKafkaRecord{acks: 1, value: []byte{0x00, 0xff, 0x10}, headers: map[string]string{}},
+		},
+		{
+			name: "no acks",
+			kafkaJob: model.KafkaJob{
+				Body: "Hello, world!",
+				Acks: model.KafkaAcksNone,
+			},
+			want: testKafkaRecord{acks: 0, value: []byte("Hello, world!"), headers: map[string]string{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.kafkaJob.Brokers = []string{broker.addr.String()}
+			tt.kafkaJob.Topic = broker.topic
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			err := (&kafkaExecutor{}).Execute(ctx, &model.Job{Type: model.JobTypeKafka, KafkaJob: &tt.kafkaJob})
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, broker.record(t))
+		})
+	}
+}
+
+func TestKafkaExecutor_unknownTopic(t *testing.T) {
+	broker := newTestKafkaBroker(t, "billing.invoices", nil, nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	job := &model.Job{
+		Type: model.JobTypeKafka,
+		KafkaJob: &model.KafkaJob{
+			Brokers: []string{broker.addr.String()},
+			Topic:   "billing.payments",
+			Body:    "Hello, world!",
+		},
+	}
+
+	err := (&kafkaExecutor{}).Execute(ctx, job)
+	assert.ErrorContains(t, err, "failed to produce record")
+}
+
+func TestKafkaExecutor_SASL(t *testing.T) {
+	broker := newTestKafkaBroker(t, "billing.invoices", map[string]string{"scheduler": "s3cr3t"}, nil)
+
+	newJob := func(password string) *model.Job {
+		return &model.Job{
+			Type: model.JobTypeKafka,
+			KafkaJob: &model.KafkaJob{
+				Brokers: []string{broker.addr.String()},
+				Topic:   broker.topic,
+				Body:    "Hello, world!",
+				SASL:    &model.KafkaSASL{Mechanism: model.KafkaSASLPlain, Username: "scheduler", Password: password},
+			},
+		}
+	}
+
+	t.Run("valid credentials", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		require.NoError(t, (&kafkaExecutor{}).Execute(ctx, newJob("s3cr3t")))
+		assert.Equal(t, []byte("Hello, world!"), broker.record(t).value)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := (&kafkaExecutor{}).Execute(ctx, newJob("wrong"))
+		assert.ErrorContains(t, err, "SASL Authentication Failed")
+	})
+}
+
+func TestKafkaExecutor_TLS(t *testing.T) {
+	ca := newTestCA(t)
+
+	certPEM, keyPEM := ca.issue(t, "kafka.internal")
+	certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+
+	broker := newTestKafkaBroker(t, "billing.invoices", nil, &tls.Config{Certificates: []tls.Certificate{certificate}})
+
+	newJob := func(tlsConfig *model.TLSConfig) *model.Job {
+		return &model.Job{
+			Type: model.JobTypeKafka,
+			KafkaJob: &model.KafkaJob{
+				Brokers: []string{broker.addr.String()},
+				Topic:   broker.topic,
+				Body:    "Hello, world!",
+				TLS:     tlsConfig,
+			},
+		}
+	}
+
+	t.Run("trusted CA", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		require.NoError(t, (&kafkaExecutor{}).Execute(ctx, newJob(&model.TLSConfig{CACert: ca.pem, ServerName: "kafka.internal"})))
+		assert.Equal(t, []byte("Hello, world!"), broker.record(t).value)
+	})
+
+	t.Run("unknown CA", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		err := (&kafkaExecutor{}).Execute(ctx, newJob(&model.TLSConfig{ServerName: "kafka.internal"}))
+		assert.ErrorContains(t, err, "certificate signed by unknown authority")
+	})
+}
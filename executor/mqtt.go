@@ -0,0 +1,184 @@
+package executor
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+const (
+	// mqttConnectTimeout bounds connecting to a broker, including the TLS handshake.
+	mqttConnectTimeout = 10 * time.Second
+
+	// mqttDisconnectQuiesce is how long a connection that is dropped from the cache waits for the messages
+	// that are being published over it.
+	mqttDisconnectQuiesce = 250 // ms
+)
+
+type mQTTExecutor struct {
+	clients *mqttClientCache // the connections of the runner's MQTT jobs
+}
+
+func (me *mQTTExecutor) Execute(ctx context.Context, j *model.Job) error {
+	mqttJob := j.MQTTJob
+
+	payload, err := decodeBody(mqttJob.Payload, mqttJob.PayloadEncoding)
+	if err != nil {
+		return err
+	}
+
+	client, err := me.clients.Client(ctx, mqttJob)
+	if err != nil {
+		return err
+	}
+
+	if err := waitMQTT(ctx, client.Publish(mqttJob.Topic, mqttJob.QoS, mqttJob.Retain, payload)); err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return nil
+}
+
+// waitMQTT waits until the operation of the token is completed, or the context is done.
+func waitMQTT(ctx context.Context, token mqtt.Token) error {
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// mqttClientCache keeps the connections to the MQTT brokers open, so the executions of the jobs with the same
+// broker, client ID, credentials and TLS options publish over the same connection. It is shared by the
+// executors of a runner. A connection that was lost is opened again by the next execution that needs it.
+type mqttClientCache struct {
+	mu          sync.Mutex
+	connections map[string]*mqttConnection
+}
+
+// mqttConnection is a cached connection. Its client is replaced when the connection is lost, while holding
+// its lock, so a broker never sees two connections with the same client ID from the same runner.
+type mqttConnection struct {
+	mu     sync.Mutex
+	client mqtt.Client
+}
+
+func newMQTTClientCache() *mqttClientCache {
+	return &mqttClientCache{connections: make(map[string]*mqttConnection)}
+}
+
+// Client returns the connected client for the job's broker, client ID, credentials and TLS options. The files
+// the TLS options refer to are read on every call, so a rotated certificate gets a new connection.
+func (mc *mqttClientCache) Client(ctx context.Context, mqttJob *model.MQTTJob) (mqtt.Client, error) {
+	config, err := loadTransportConfig(mqttJob.TLS, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := mc.connection(mqttClientKey(mqttJob, config))
+
+	connection.mu.Lock()
+	defer connection.mu.Unlock()
+
+	if connection.client != nil && connection.client.IsConnectionOpen() {
+		return connection.client, nil
+	}
+
+	options := mqtt.NewClientOptions().
+		AddBroker(mqttJob.BrokerURL).
+		SetClientID(mqttJob.ClientID).
+		SetUsername(mqttJob.Username).
+		SetPassword(mqttJob.Password).
+		SetCleanSession(true).
+		SetAutoReconnect(false). // the connection is opened again by the next execution
+		SetConnectTimeout(mqttConnectTimeout)
+
+	if mqttJob.ClientID == "" {
+		options.SetClientID(randomMQTTClientID())
+	}
+
+	if mqttJob.TLS != nil {
+		tlsConfig, err := config.tlsConfig(nil)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errTLSConfig, err)
+		}
+		options.SetTLSConfig(tlsConfig)
+	}
+
+	client := mqtt.NewClient(options)
+
+	token := client.Connect()
+	if err := waitMQTT(ctx, token); err != nil {
+		// a connection that is established after the execution gave up on it isn't used
+		go func() {
+			token.Wait()
+			client.Disconnect(0)
+		}()
+
+		return nil, fmt.Errorf("failed to connect to MQTT broker: %w", err)
+	}
+
+	connection.client = client
+
+	return client, nil
+}
+
+// connection returns the cached connection with the key, adding it to the cache if needed.
+func (mc *mqttClientCache) connection(key string) *mqttConnection {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+
+	if connection, ok := mc.connections[key]; ok {
+		return connection
+	}
+
+	if len(mc.connections) >= maxCachedClients {
+		for _, cached := range mc.connections {
+			go cached.disconnect()
+		}
+		mc.connections = make(map[string]*mqttConnection)
+	}
+
+	connection := &mqttConnection{}
+	mc.connections[key] = connection
+
+	return connection
+}
+
+func (c *mqttConnection) disconnect() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.client != nil {
+		c.client.Disconnect(mqttDisconnectQuiesce)
+	}
+}
+
+// mqttClientKey identifies the connection of a job. The password and the private key are hashed, so they
+// aren't kept around in the cache key.
+func mqttClientKey(mqttJob *model.MQTTJob, config *transportConfig) string {
+	hash := sha256.New()
+	for _, part := range []string{mqttJob.BrokerURL, mqttJob.ClientID, mqttJob.Username, mqttJob.Password, config.key()} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// randomMQTTClientID returns a client ID for a job without one. It is at most 23 characters long, the
+// longest client ID every broker has to accept.
+func randomMQTTClientID() string {
+	b := make([]byte, 6)
+	_, _ = rand.Read(b)
+
+	return "scheduler-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,260 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	mqtt "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testMQTTBroker is an embedded MQTT broker for the tests. It accepts the connections of publishers, checks
+// their credentials and records the messages they publish.
+type testMQTTBroker struct {
+	addr   string
+	server *mqtt.Server
+	hook   *testMQTTHook
+}
+
+// testMQTTMessage is a message the test broker received.
+type testMQTTMessage struct {
+	clientID string
+	topic    string
+	qos      byte
+	retain   bool
+	payload  []byte
+}
+
+// testMQTTHook counts the connections to the test broker and records the messages it receives.
+type testMQTTHook struct {
+	mqtt.HookBase
+	connections int32
+	messages    chan testMQTTMessage
+}
+
+func (h *testMQTTHook) ID() string {
+	return "test"
+}
+
+func (h *testMQTTHook) Provides(b byte) bool {
+	return b == mqtt.OnConnect || b == mqtt.OnPublish
+}
+
+func (h *testMQTTHook) OnConnect(cl *mqtt.Client, pk packets.Packet) error {
+	atomic.AddInt32(&h.connections, 1)
+	return nil
+}
+
+func (h *testMQTTHook) OnPublish(cl *mqtt.Client, pk packets.Packet) (packets.Packet, error) {
+	h.messages <- testMQTTMessage{
+		clientID: cl.ID,
+		topic:    pk.TopicName,
+		qos:      pk.FixedHeader.Qos,
+		retain:   pk.FixedHeader.Retain,
+		payload:  pk.Payload,
+	}
+
+	return pk, nil
+}
+
+// newTestMQTTBroker starts a broker that requires the credentials of one of the users, or none if users is nil.
+// If tlsConfig isn't nil, the broker only accepts TLS connections.
+func newTestMQTTBroker(t *testing.T, users map[string]string, tlsConfig *tls.Config) *testMQTTBroker {
+	logger := zerolog.Nop()
+	server := mqtt.New(&mqtt.Options{Logger: &logger})
+
+	if users == nil {
+		require.NoError(t, server.AddHook(new(auth.AllowHook), nil))
+	} else {
+		ledger := &auth.Ledger{Users: auth.Users{}}
+		for username, password := range users {
+			ledger.Users[username] = auth.UserRule{Username: auth.RString(username), Password: auth.RString(password)}
+		}
+		require.NoError(t, server.AddHook(new(auth.Hook), &auth.Options{Ledger: ledger}))
+	}
+
+	hook := &testMQTTHook{messages: make(chan testMQTTMessage, 10)}
+	require.NoError(t, server.AddHook(hook, nil))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	addr := listener.Addr().String()
+	if tlsConfig != nil {
+		listener = tls.NewListener(listener, tlsConfig)
+	}
+
+	require.NoError(t, server.AddListener(listeners.NewNet("test", listener)))
+	require.NoError(t, server.Serve())
+
+	t.Cleanup(func() { _ = server.Close() })
+
+	return &testMQTTBroker{addr: addr, server: server, hook: hook}
+}
+
+// connectionCount returns how many connections the broker accepted.
+func (b *testMQTTBroker) connectionCount() int {
+	return int(atomic.LoadInt32(&b.hook.connections))
+}
+
+// dropConnections closes the connections, like a broker that restarts.
+func (b *testMQTTBroker) dropConnections() {
+	for _, client := range b.server.Clients.GetAll() {
+		client.Stop(errors.New("connection dropped"))
+	}
+}
+
+// message returns the next message the broker receives.
+func (b *testMQTTBroker) message(t *testing.T) testMQTTMessage {
+	select {
+	case message := <-b.hook.messages:
+		return message
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message received")
+		return testMQTTMessage{}
+	}
+}
+
+func executeMQTT(t *testing.T, executor *mQTTExecutor, mqttJob *model.MQTTJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return executor.Execute(ctx, &model.Job{Type: model.JobTypeMQTT, MQTTJob: mqttJob})
+}
+
+func TestMQTTExecutor_Execute(t *testing.T) {
+	broker := newTestMQTTBroker(t, nil, nil)
+	executor := &mQTTExecutor{clients: newMQTTClientCache()}
+
+	base64Encoding := model.BodyEncodingBase64
+
+	tests := []struct {
+		name    string
+		mqttJob model.MQTTJob
+		want    testMQTTMessage
+	}{
+		{
+			name:    "QoS 0",
+			mqttJob: model.MQTTJob{Topic: "chargers/CP-1042/config", Payload: `{"heartbeat_interval": 300}`},
+			want:    testMQTTMessage{topic: "chargers/CP-1042/config", qos: 0, payload: []byte(`{"heartbeat_interval": 300}`)},
+		},
+		{
+			name:    "QoS 1 retained",
+			mqttJob: model.MQTTJob{Topic: "chargers/firmware-window", QoS: 1, Retain: true, Payload: "02:00-04:00"},
+			want:    testMQTTMessage{topic: "chargers/firmware-window", qos: 1, retain: true, payload: []byte("02:00-04:00")},
+		},
+		{
+			name: "QoS 2 with base64 encoded payload",
+			mqttJob: model.MQTTJob{
+				Topic:           "chargers/CP-1042/firmware",
+				QoS:             2,
+				Payload:         base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0x10}),
+				PayloadEncoding: &base64Encoding,
+			},
+			want: testMQTTMessage{topic: "chargers/CP-1042/firmware", qos: 2, payload: []byte{0x00, 0xff, 0x10}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mqttJob.BrokerURL = "tcp://" + broker.addr
+			tt.mqttJob.ClientID = "scheduler"
+			tt.want.clientID = "scheduler"
+
+			require.NoError(t, executeMQTT(t, executor, &tt.mqttJob))
+			assert.Equal(t, tt.want, broker.message(t))
+		})
+	}
+
+	// the jobs with the same broker and client ID share the connection
+	assert.Equal(t, 1, broker.connectionCount())
+}
+
+func TestMQTTExecutor_randomClientID(t *testing.T) {
+	broker := newTestMQTTBroker(t, nil, nil)
+	executor := &mQTTExecutor{clients: newMQTTClientCache()}
+
+	require.NoError(t, executeMQTT(t, executor, &model.MQTTJob{BrokerURL: "mqtt://" + broker.addr, Topic: "chargers/announcements"}))
+
+	clientID := broker.message(t).clientID
+	assert.Regexp(t, `^scheduler-[0-9a-f]{12}$`, clientID)
+	assert.LessOrEqual(t, len(clientID), 23)
+}
+
+func TestMQTTExecutor_reconnect(t *testing.T) {
+	broker := newTestMQTTBroker(t, nil, nil)
+	executor := &mQTTExecutor{clients: newMQTTClientCache()}
+
+	mqttJob := &model.MQTTJob{BrokerURL: "tcp://" + broker.addr, ClientID: "scheduler", Topic: "chargers/announcements", QoS: 1}
+
+	require.NoError(t, executeMQTT(t, executor, mqttJob))
+	broker.message(t)
+
+	client, err := executor.clients.Client(context.Background(), mqttJob)
+	require.NoError(t, err)
+
+	broker.dropConnections()
+	assert.Eventually(t, func() bool { return !client.IsConnectionOpen() }, 5*time.Second, 10*time.Millisecond)
+
+	// the next execution opens the connection again
+	require.NoError(t, executeMQTT(t, executor, mqttJob))
+	broker.message(t)
+
+	assert.Equal(t, 2, broker.connectionCount())
+}
+
+func TestMQTTExecutor_credentials(t *testing.T) {
+	broker := newTestMQTTBroker(t, map[string]string{"scheduler": "s3cr3t"}, nil)
+	executor := &mQTTExecutor{clients: newMQTTClientCache()}
+
+	newJob := func(password string) *model.MQTTJob {
+		return &model.MQTTJob{BrokerURL: "tcp://" + broker.addr, Topic: "chargers/announcements", Username: "scheduler", Password: password}
+	}
+
+	t.Run("valid credentials", func(t *testing.T) {
+		require.NoError(t, executeMQTT(t, executor, newJob("s3cr3t")))
+		assert.Equal(t, "chargers/announcements", broker.message(t).topic)
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		err := executeMQTT(t, executor, newJob("wrong"))
+		assert.ErrorContains(t, err, "failed to connect to MQTT broker")
+	})
+}
+
+func TestMQTTExecutor_TLS(t *testing.T) {
+	ca := newTestCA(t)
+
+	certPEM, keyPEM := ca.issue(t, "mqtt.internal")
+	certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+
+	broker := newTestMQTTBroker(t, nil, &tls.Config{Certificates: []tls.Certificate{certificate}})
+	executor := &mQTTExecutor{clients: newMQTTClientCache()}
+
+	newJob := func(tlsConfig *model.TLSConfig) *model.MQTTJob {
+		return &model.MQTTJob{BrokerURL: "mqtts://" + broker.addr, Topic: "chargers/announcements", TLS: tlsConfig}
+	}
+
+	t.Run("trusted CA", func(t *testing.T) {
+		require.NoError(t, executeMQTT(t, executor, newJob(&model.TLSConfig{CACert: ca.pem, ServerName: "mqtt.internal"})))
+		assert.Equal(t, "chargers/announcements", broker.message(t).topic)
+	})
+
+	t.Run("unknown CA", func(t *testing.T) {
+		err := executeMQTT(t, executor, newJob(&model.TLSConfig{ServerName: "mqtt.internal"}))
+		assert.ErrorContains(t, err, "certificate signed by unknown authority")
+	})
+}
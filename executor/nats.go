@@ -0,0 +1,107 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsConnectTimeout bounds connecting to a server, including the TLS handshake.
+const natsConnectTimeout = 10 * time.Second
+
+type nATSExecutor struct{}
+
+func (ne *nATSExecutor) Execute(ctx context.Context, j *model.Job) error {
+	natsJob := j.NATSJob
+
+	body, err := decodeBody(natsJob.Body, natsJob.BodyEncoding)
+	if err != nil {
+		return err
+	}
+
+	conn, err := ne.connect(ctx, natsJob)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	defer conn.Close()
+
+	msg := nats.NewMsg(natsJob.Subject)
+	msg.Data = body
+	for key, value := range natsJob.Headers {
+		msg.Header.Set(key, value)
+	}
+
+	if natsJob.JetStream {
+		return ne.publishJetStream(ctx, conn, natsJob, msg)
+	}
+
+	if err := conn.PublishMsg(msg); err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	// the flush returns once the server has processed the message
+	if err := conn.FlushWithContext(ctx); err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return nil
+}
+
+// publishJetStream publishes the message to the stream that captures its subject and waits for the stream's
+// ack. A message the stream already has, with the same msg ID, counts as published.
+func (ne *nATSExecutor) publishJetStream(ctx context.Context, conn *nats.Conn, natsJob *model.NATSJob, msg *nats.Msg) error {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return fmt.Errorf("failed to open JetStream: %w", err)
+	}
+
+	var options []jetstream.PublishOpt
+	if natsJob.MsgID != "" {
+		options = append(options, jetstream.WithMsgID(natsJob.MsgID))
+	}
+
+	if _, err := js.PublishMsg(ctx, msg, options...); err != nil {
+		return fmt.Errorf("failed to publish message to JetStream: %w", err)
+	}
+
+	return nil
+}
+
+// connect opens a connection to one of the job's servers. The connection isn't reconnected when it is lost,
+// the execution fails instead and is retried with the job's retry policy.
+func (ne *nATSExecutor) connect(ctx context.Context, natsJob *model.NATSJob) (*nats.Conn, error) {
+	timeout := natsConnectTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+
+	options := []nats.Option{
+		nats.Name("distributed-scheduler"),
+		nats.Timeout(timeout),
+		nats.NoReconnect(),
+	}
+
+	switch {
+	case natsJob.Username != "":
+		options = append(options, nats.UserInfo(natsJob.Username, natsJob.Password))
+	case natsJob.Token != "":
+		options = append(options, nats.Token(natsJob.Token))
+	case natsJob.CredentialsFile != "":
+		options = append(options, nats.UserCredentials(natsJob.CredentialsFile))
+	}
+
+	if natsJob.TLS != nil {
+		tlsConfig, err := loadTLSConfig(natsJob.TLS)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, nats.Secure(tlsConfig))
+	}
+
+	return nats.Connect(strings.Join(natsJob.Servers, ","), options...)
+}
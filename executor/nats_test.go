@@ -0,0 +1,220 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestNATSServer starts an embedded NATS server with JetStream enabled. The options, if any, configure the
+// server further, e.g. its users or TLS.
+func newTestNATSServer(t *testing.T, configure func(*server.Options)) *server.Server {
+	options := &server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	}
+	if configure != nil {
+		configure(options)
+	}
+
+	natsServer, err := server.NewServer(options)
+	require.NoError(t, err)
+
+	go natsServer.Start()
+	t.Cleanup(natsServer.Shutdown)
+
+	require.True(t, natsServer.ReadyForConnections(5*time.Second), "NATS server isn't ready")
+
+	return natsServer
+}
+
+func executeNATS(t *testing.T, natsJob *model.NATSJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return (&nATSExecutor{}).Execute(ctx, &model.Job{Type: model.JobTypeNATS, NATSJob: natsJob})
+}
+
+func TestNATSExecutor_Execute(t *testing.T) {
+	natsServer := newTestNATSServer(t, nil)
+
+	conn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	subscription, err := conn.SubscribeSync("billing.>")
+	require.NoError(t, err)
+	require.NoError(t, conn.Flush())
+
+	base64Encoding := model.BodyEncodingBase64
+
+	tests := []struct {
+		name        string
+		natsJob     model.NATSJob
+		wantSubject string
+		wantHeaders nats.Header
+		wantBody    []byte
+	}{
+		{
+			name:        "plain publish",
+			natsJob:     model.NATSJob{Subject: "billing.invoices.close", Body: `{"period": "monthly"}`},
+			wantSubject: "billing.invoices.close",
+			wantBody:    []byte(`{"period": "monthly"}`),
+		},
+		{
+			name: "headers and base64 encoded body",
+			natsJob: model.NATSJob{
+				Subject:      "billing.exports",
+				Headers:      map[string]string{"Source": "scheduler"},
+				Body:         base64.StdEncoding.EncodeToString([]byte{0x00, 0xff, 0x10}),
+				BodyEncoding: &base64Encoding,
+			},
+			wantSubject: "billing.exports",
+			wantHeaders: nats.Header{"Source": []string{"scheduler"}},
+			wantBody:    []byte{0x00, 0xff, 0x10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.natsJob.Servers = []string{natsServer.ClientURL()}
+
+			require.NoError(t, executeNATS(t, &tt.natsJob))
+
+			msg, err := subscription.NextMsg(5 * time.Second)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantSubject, msg.Subject)
+			assert.Equal(t, tt.wantBody, msg.Data)
+			for key := range tt.wantHeaders {
+				assert.Equal(t, tt.wantHeaders.Get(key), msg.Header.Get(key))
+			}
+		})
+	}
+}
+
+func TestNATSExecutor_JetStream(t *testing.T) {
+	natsServer := newTestNATSServer(t, nil)
+
+	conn, err := nats.Connect(natsServer.ClientURL())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := js.CreateStream(ctx, jetstream.StreamConfig{Name: "BILLING", Subjects: []string{"billing.>"}})
+	require.NoError(t, err)
+
+	natsJob := &model.NATSJob{
+		Servers:   []string{natsServer.ClientURL()},
+		Subject:   "billing.invoices.close",
+		Body:      `{"period": "monthly"}`,
+		JetStream: true,
+		MsgID:     "invoices-2023-10",
+	}
+
+	t.Run("duplicate msg ID", func(t *testing.T) {
+		require.NoError(t, executeNATS(t, natsJob))
+		require.NoError(t, executeNATS(t, natsJob))
+
+		info, err := stream.Info(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), info.State.Msgs)
+
+		msg, err := stream.GetLastMsgForSubject(ctx, "billing.invoices.close")
+		require.NoError(t, err)
+		assert.Equal(t, []byte(`{"period": "monthly"}`), msg.Data)
+		assert.Equal(t, "invoices-2023-10", msg.Header.Get(jetstream.MsgIDHeader))
+	})
+
+	t.Run("without msg ID", func(t *testing.T) {
+		withoutMsgID := *natsJob
+		withoutMsgID.MsgID = ""
+
+		require.NoError(t, executeNATS(t, &withoutMsgID))
+		require.NoError(t, executeNATS(t, &withoutMsgID))
+
+		info, err := stream.Info(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), info.State.Msgs)
+	})
+
+	t.Run("no stream for the subject", func(t *testing.T) {
+		noStream := *natsJob
+		noStream.Subject = "payments.refunds"
+
+		err := executeNATS(t, &noStream)
+		assert.ErrorContains(t, err, "failed to publish message to JetStream")
+	})
+}
+
+func TestNATSExecutor_credentials(t *testing.T) {
+	natsServer := newTestNATSServer(t, func(options *server.Options) {
+		options.Users = []*server.User{{Username: "scheduler", Password: "s3cr3t"}}
+	})
+
+	newJob := func(password string) *model.NATSJob {
+		return &model.NATSJob{
+			Servers:  []string{natsServer.ClientURL()},
+			Subject:  "billing.invoices.close",
+			Username: "scheduler",
+			Password: password,
+		}
+	}
+
+	t.Run("valid credentials", func(t *testing.T) {
+		require.NoError(t, executeNATS(t, newJob("s3cr3t")))
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		err := executeNATS(t, newJob("wrong"))
+		assert.ErrorContains(t, err, "failed to connect to NATS")
+	})
+}
+
+func TestNATSExecutor_TLS(t *testing.T) {
+	ca := newTestCA(t)
+
+	certPEM, keyPEM := ca.issue(t, "nats.internal")
+	certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+
+	natsServer := newTestNATSServer(t, func(options *server.Options) {
+		options.TLS = true
+		options.TLSConfig = &tls.Config{Certificates: []tls.Certificate{certificate}}
+	})
+
+	newJob := func(tlsConfig *model.TLSConfig) *model.NATSJob {
+		return &model.NATSJob{
+			Servers: []string{natsServer.ClientURL()},
+			Subject: "billing.invoices.close",
+			TLS:     tlsConfig,
+		}
+	}
+
+	t.Run("trusted CA", func(t *testing.T) {
+		require.NoError(t, executeNATS(t, newJob(&model.TLSConfig{CACert: ca.pem, ServerName: "nats.internal"})))
+	})
+
+	t.Run("unknown CA", func(t *testing.T) {
+		err := executeNATS(t, newJob(&model.TLSConfig{ServerName: "nats.internal"}))
+		assert.ErrorContains(t, err, "certificate signed by unknown authority")
+	})
+}
@@ -0,0 +1,191 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+)
+
+// tokenExpiryMargin is how long before a token expires it is no longer used, so a request doesn't
+// reach the endpoint with a token that expired on the way.
+const tokenExpiryMargin = 30 * time.Second
+
+// maxCachedTokens bounds the number of client credentials the tokens are cached for. When it is reached the
+// cache is emptied, e.g. after many secret rotations, and the tokens are fetched again when needed.
+const maxCachedTokens = 256
+
+// maxTokenResponseSize bounds the size of a token endpoint's response that is read.
+const maxTokenResponseSize = 1 << 20
+
+var errTokenNotIssued = errors.New("failed to get oauth2 token")
+
+// tokenCache fetches OAuth2 access tokens with the client credentials grant and caches them until shortly
+// before they expire. It is shared by the executors of a runner, so jobs with the same credentials, and the
+// same TLS and proxy configuration, share their tokens.
+type tokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]*cachedToken
+}
+
+// cachedToken is the token of one set of client credentials. Its lock is held while the token is fetched,
+// so concurrent executions wait for one token instead of each fetching their own.
+type cachedToken struct {
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time // zero if the token endpoint didn't say when the token expires
+}
+
+func newTokenCache() *tokenCache {
+	return &tokenCache{
+		tokens: make(map[string]*cachedToken),
+	}
+}
+
+// Token returns a valid access token for the auth's client credentials, fetching a new one with the job's
+// client if needed, so the token endpoint is reached with the job's TLS and proxy configuration.
+func (tc *tokenCache) Token(ctx context.Context, client jobClient, auth model.Auth) (string, error) {
+	cached := tc.entry(client, auth)
+
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+
+	if cached.accessToken != "" && (cached.expiresAt.IsZero() || time.Now().Before(cached.expiresAt)) {
+		return cached.accessToken, nil
+	}
+
+	accessToken, expiresIn, err := fetchToken(ctx, client, auth)
+	if err != nil {
+		return "", err
+	}
+
+	cached.accessToken = accessToken
+	cached.expiresAt = time.Time{}
+	if expiresIn > 0 {
+		cached.expiresAt = time.Now().Add(tokenLifetime(expiresIn))
+	}
+
+	return accessToken, nil
+}
+
+// Invalidate drops the given access token of the auth's client credentials, e.g. because it was rejected,
+// so the next call to Token fetches a new one. A token that was replaced in the meantime is kept.
+func (tc *tokenCache) Invalidate(client jobClient, auth model.Auth, accessToken string) {
+	cached := tc.entry(client, auth)
+
+	cached.mu.Lock()
+	defer cached.mu.Unlock()
+
+	if cached.accessToken == accessToken {
+		cached.accessToken = ""
+	}
+}
+
+func (tc *tokenCache) entry(client jobClient, auth model.Auth) *cachedToken {
+	key := tokenKey(client, auth)
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	cached, ok := tc.tokens[key]
+	if !ok {
+		if len(tc.tokens) >= maxCachedTokens {
+			tc.tokens = make(map[string]*cachedToken)
+		}
+
+		cached = &cachedToken{}
+		tc.tokens[key] = cached
+	}
+
+	return cached
+}
+
+// tokenLifetime returns how long a token that expires in expiresIn is used. A token that expires within the
+// expiry margin is used for half its lifetime, rather than not at all.
+func tokenLifetime(expiresIn time.Duration) time.Duration {
+	if expiresIn <= tokenExpiryMargin {
+		return expiresIn / 2
+	}
+
+	return expiresIn - tokenExpiryMargin
+}
+
+// tokenKey identifies the client credentials of the auth and the configuration of the client the token is fetched
+// with. The secret is hashed, so it isn't kept around in the key.
+func tokenKey(client jobClient, auth model.Auth) string {
+	hash := sha256.New()
+	for _, part := range []string{client.key, auth.TokenURL.String, auth.ClientID.String, auth.ClientSecret.String, strings.Join(auth.Scopes, " "), auth.Audience.String} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// fetchToken requests a new access token from the auth's token endpoint (RFC 6749, section 4.4).
+// The client authenticates with HTTP basic authentication.
+func fetchToken(ctx context.Context, client jobClient, auth model.Auth) (string, time.Duration, error) {
+	// the token request follows redirects like any request without a redirect policy, rather than the job's
+	ctx = context.WithValue(ctx, redirectsKey{}, nil)
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(auth.Scopes) > 0 {
+		form.Set("scope", strings.Join(auth.Scopes, " "))
+	}
+	if auth.Audience.Valid && auth.Audience.String != "" {
+		form.Set("audience", auth.Audience.String)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, auth.TokenURL.String, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", errTokenNotIssued, err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(auth.ClientID.String), url.QueryEscape(auth.ClientSecret.String))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", errTokenNotIssued, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenResponseSize))
+	if err != nil {
+		return "", 0, fmt.Errorf("%w: %w", errTokenNotIssued, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, fmt.Errorf("%w: token endpoint responded with status %d", errTokenNotIssued, resp.StatusCode)
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", 0, fmt.Errorf("%w: invalid token response: %w", errTokenNotIssued, err)
+	}
+
+	if token.AccessToken == "" {
+		return "", 0, fmt.Errorf("%w: token response has no access token", errTokenNotIssued)
+	}
+
+	if token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer") {
+		return "", 0, fmt.Errorf("%w: unsupported token type %s", errTokenNotIssued, token.TokenType)
+	}
+
+	return token.AccessToken, time.Duration(token.ExpiresIn) * time.Second, nil
+}
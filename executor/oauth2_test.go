@@ -0,0 +1,217 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+// newTokenServer returns a token server that issues the tokens "token-1", "token-2", ... valid for expiresIn seconds.
+func newTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *int32) {
+	var issued int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "scheduler" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		assert.Equal(t, "client_credentials", r.FormValue("grant_type"))
+		assert.Equal(t, "jobs:read jobs:write", r.FormValue("scope"))
+		assert.Equal(t, "https://api.example.com", r.FormValue("audience"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": fmt.Sprintf("token-%d", atomic.AddInt32(&issued, 1)),
+			"token_type":   "Bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+
+	return server, &issued
+}
+
+func newOAuth2Job(tokenURL, url string) *model.Job {
+	return &model.Job{
+		Type: model.JobTypeHTTP,
+		HTTPJob: &model.HTTPJob{
+			Method: "GET",
+			URL:    url,
+			Auth: model.Auth{
+				Type:         model.AuthTypeOAuth2ClientCredentials,
+				TokenURL:     null.StringFrom(tokenURL),
+				ClientID:     null.StringFrom("scheduler"),
+				ClientSecret: null.StringFrom("secret"),
+				Scopes:       []string{"jobs:read", "jobs:write"},
+				Audience:     null.StringFrom("https://api.example.com"),
+			},
+		},
+	}
+}
+
+func TestHTTPExecutor_OAuth2ClientCredentials(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("token is cached until it expires", func(t *testing.T) {
+		tokenServer, issued := newTokenServer(t, 3600)
+		defer tokenServer.Close()
+
+		var authorizations []string
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorizations = append(authorizations, r.Header.Get("Authorization"))
+		}))
+		defer api.Close()
+
+		factory := NewFactory(http.DefaultClient)
+		j := newOAuth2Job(tokenServer.URL, api.URL)
+
+		for i := 0; i < 3; i++ {
+			executor, err := factory.NewExecutor(j)
+			assert.Nil(t, err)
+			assert.Nil(t, executor.Execute(ctx, j))
+		}
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(issued))
+		assert.Equal(t, []string{"Bearer token-1", "Bearer token-1", "Bearer token-1"}, authorizations)
+	})
+
+	t.Run("expired token is replaced", func(t *testing.T) {
+		// the token expires within the expiry margin, so it is used for half its lifetime
+		tokenServer, issued := newTokenServer(t, 1)
+		defer tokenServer.Close()
+
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer api.Close()
+
+		executor := &hTTPExecutor{Client: http.DefaultClient, tokens: newTokenCache()}
+		j := newOAuth2Job(tokenServer.URL, api.URL)
+
+		assert.Nil(t, executor.Execute(ctx, j))
+		assert.Nil(t, executor.Execute(ctx, j))
+		assert.Equal(t, int32(1), atomic.LoadInt32(issued))
+
+		time.Sleep(600 * time.Millisecond)
+
+		assert.Nil(t, executor.Execute(ctx, j))
+		assert.Equal(t, int32(2), atomic.LoadInt32(issued))
+	})
+
+	t.Run("rejected token is refreshed once", func(t *testing.T) {
+		tokenServer, issued := newTokenServer(t, 3600)
+		defer tokenServer.Close()
+
+		// the first token is revoked
+		var bodies []string
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body struct{ Hello string }
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			bodies = append(bodies, body.Hello)
+
+			if r.Header.Get("Authorization") == "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		defer api.Close()
+
+		executor := &hTTPExecutor{Client: http.DefaultClient, tokens: newTokenCache()}
+		j := newOAuth2Job(tokenServer.URL, api.URL)
+		j.HTTPJob.Method = "POST"
+		j.HTTPJob.Body = null.StringFrom(`{"hello": "world"}`)
+
+		assert.Nil(t, executor.Execute(ctx, j))
+		assert.Equal(t, int32(2), atomic.LoadInt32(issued))
+
+		// the request is sent again with its body
+		assert.Equal(t, []string{"world", "world"}, bodies)
+	})
+
+	t.Run("token that is rejected again fails the execution", func(t *testing.T) {
+		tokenServer, issued := newTokenServer(t, 3600)
+		defer tokenServer.Close()
+
+		api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer api.Close()
+
+		executor := &hTTPExecutor{Client: http.DefaultClient, tokens: newTokenCache()}
+		err := executor.Execute(ctx, newOAuth2Job(tokenServer.URL, api.URL))
+
+		assert.Equal(t, &model.HTTPStatusError{StatusCode: http.StatusUnauthorized}, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(issued))
+	})
+
+	t.Run("invalid client credentials", func(t *testing.T) {
+		tokenServer, _ := newTokenServer(t, 3600)
+		defer tokenServer.Close()
+
+		executor := &hTTPExecutor{Client: http.DefaultClient, tokens: newTokenCache()}
+		j := newOAuth2Job(tokenServer.URL, "http://localhost")
+		j.HTTPJob.Auth.ClientSecret = null.StringFrom("wrong")
+
+		err := executor.Execute(ctx, j)
+		assert.ErrorIs(t, err, errTokenNotIssued)
+		assert.EqualError(t, err, "failed to get oauth2 token: token endpoint responded with status 401")
+	})
+
+	t.Run("token is fetched with the job's TLS configuration", func(t *testing.T) {
+		var issued int32
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/token" {
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"access_token": fmt.Sprintf("token-%d", atomic.AddInt32(&issued, 1)),
+					"expires_in":   3600,
+				})
+			}
+		}))
+		defer server.Close()
+
+		caCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+
+		factory := NewFactory(http.DefaultClient)
+		j := newOAuth2Job(server.URL+"/token", server.URL)
+		j.HTTPJob.TLS = &model.TLSConfig{CACert: string(caCert)}
+
+		executor, err := factory.NewExecutor(j)
+		assert.Nil(t, err)
+		assert.Nil(t, executor.Execute(ctx, j))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&issued))
+
+		// the token isn't shared with a job that reaches the token endpoint with another configuration
+		j.HTTPJob.TLS = nil
+		err = executor.Execute(ctx, j)
+		assert.ErrorIs(t, err, errTokenNotIssued)
+		assert.ErrorContains(t, err, "certificate signed by unknown authority")
+	})
+}
+
+func TestTokenCache_Bounded(t *testing.T) {
+	tc := newTokenCache()
+	auth := newOAuth2Job("https://auth.example.com/token", "http://localhost").HTTPJob.Auth
+
+	for i := 0; i < maxCachedTokens+1; i++ {
+		auth.ClientID = null.StringFrom(fmt.Sprintf("client-%d", i))
+		tc.entry(jobClient{HttpClient: http.DefaultClient}, auth)
+	}
+
+	// the cache was emptied when it was full, so only the last client's entry is left
+	assert.Len(t, tc.tokens, 1)
+}
+
+func TestTokenLifetime(t *testing.T) {
+	assert.Equal(t, 3570*time.Second, tokenLifetime(time.Hour))
+	assert.Equal(t, 15*time.Second, tokenLifetime(30*time.Second))
+	assert.Equal(t, 5*time.Second, tokenLifetime(10*time.Second))
+}
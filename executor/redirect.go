@@ -0,0 +1,68 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+)
+
+// redirectsKey is the context key of the redirectRecorder of a request.
+type redirectsKey struct{}
+
+// redirectRecorder applies the redirect policy of an HTTP job to the redirects of its request, and records
+// the redirects that are followed.
+type redirectRecorder struct {
+	policy *model.RedirectPolicy
+	hops   []model.RedirectHop
+}
+
+// withRedirectPolicy returns a context for a request that follows the redirects the policy allows, and the
+// recorder of the redirects the request follows.
+func withRedirectPolicy(ctx context.Context, policy *model.RedirectPolicy) (context.Context, *redirectRecorder) {
+	recorder := &redirectRecorder{policy: policy}
+
+	return context.WithValue(ctx, redirectsKey{}, recorder), recorder
+}
+
+// checkRedirect is the CheckRedirect function of the runner's clients. A request without a redirect policy,
+// e.g. that of an OAuth2 token, follows redirects like it would with the default function.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	recorder, ok := req.Context().Value(redirectsKey{}).(*redirectRecorder)
+	if !ok {
+		if len(via) >= model.DefaultMaxRedirects {
+			return errors.New("stopped after 10 redirects")
+		}
+
+		return nil
+	}
+
+	// a redirect that isn't followed leaves the redirect response as the response, via holds the
+	// original request and the requests of the redirects that were followed
+	if !recorder.policy.Allows(via[0].URL, req.URL, len(via)-1) {
+		return http.ErrUseLastResponse
+	}
+
+	hop := model.RedirectHop{Method: req.Method, URL: model.RedactURL(req.URL.String())}
+	if req.Response != nil {
+		hop.StatusCode = req.Response.StatusCode
+	}
+	recorder.hops = append(recorder.hops, hop)
+
+	return nil
+}
+
+// withCheckRedirect returns a copy of the client that follows redirects with checkRedirect, or the client
+// itself if it isn't an *http.Client.
+func withCheckRedirect(client HttpClient) HttpClient {
+	httpClient, ok := client.(*http.Client)
+	if !ok {
+		return client
+	}
+
+	withPolicy := *httpClient
+	withPolicy.CheckRedirect = checkRedirect
+
+	return &withPolicy
+}
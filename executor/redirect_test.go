@@ -0,0 +1,132 @@
+package executor
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestHTTPExecutor_redirects(t *testing.T) {
+	ctx := context.Background()
+
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer other.Close()
+
+	// /hop/3 redirects to /hop/2, /hop/2 to /hop/1, /hop/1 to /done with a token; /elsewhere redirects to another host
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/done":
+			_, _ = w.Write([]byte(r.Method))
+		case r.URL.Path == "/hop/1":
+			http.Redirect(w, r, "/done?token=s3cr3t", http.StatusFound)
+		case strings.HasPrefix(r.URL.Path, "/hop/"):
+			http.Redirect(w, r, "/hop/"+string(r.URL.Path[len(r.URL.Path)-1]-1), http.StatusMovedPermanently)
+		case r.URL.Path == "/elsewhere":
+			http.Redirect(w, r, strings.Replace(other.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	factory := NewFactory(server.Client())
+
+	execute := func(j *model.Job) error {
+		executor, err := factory.NewExecutor(j)
+		require.NoError(t, err)
+
+		return executor.Execute(ctx, j)
+	}
+
+	newJob := func(path string, policy *model.RedirectPolicy) *model.Job {
+		return &model.Job{
+			Type: model.JobTypeHTTP,
+			HTTPJob: &model.HTTPJob{
+				Method:    "GET",
+				URL:       server.URL + path,
+				Auth:      model.Auth{Type: model.AuthTypeNone},
+				Redirects: policy,
+			},
+		}
+	}
+
+	t.Run("redirects are followed and recorded without their query", func(t *testing.T) {
+		j := newJob("/hop/2", nil)
+		j.HTTPJob.Method = "POST"
+		j.HTTPJob.Body = null.StringFrom("hello")
+
+		assert.Nil(t, execute(j))
+		assert.Equal(t, []model.RedirectHop{
+			{StatusCode: http.StatusMovedPermanently, Method: "GET", URL: server.URL + "/hop/1"},
+			{StatusCode: http.StatusFound, Method: "GET", URL: server.URL + "/done"},
+		}, j.Response.Redirects)
+		assert.Equal(t, "GET", j.Response.Body)
+	})
+
+	t.Run("redirects are not followed", func(t *testing.T) {
+		j := newJob("/hop/1", &model.RedirectPolicy{Mode: model.RedirectModeNone})
+
+		assert.Equal(t, &model.HTTPStatusError{StatusCode: http.StatusFound}, execute(j))
+		assert.Equal(t, "/done", j.Response.Headers["Location"])
+		assert.Empty(t, j.Response.Redirects)
+
+		// the redirect can be the expected response
+		j.HTTPJob.ValidResponseCodes = []int{http.StatusFound}
+		assert.Nil(t, execute(j))
+	})
+
+	t.Run("redirects to other hosts are not followed", func(t *testing.T) {
+		j := newJob("/elsewhere", &model.RedirectPolicy{Mode: model.RedirectModeSameHost})
+		assert.Equal(t, &model.HTTPStatusError{StatusCode: http.StatusFound}, execute(j))
+
+		j = newJob("/hop/1", &model.RedirectPolicy{Mode: model.RedirectModeSameHost})
+		assert.Nil(t, execute(j))
+		assert.Len(t, j.Response.Redirects, 1)
+	})
+
+	t.Run("max hops", func(t *testing.T) {
+		j := newJob("/hop/3", &model.RedirectPolicy{MaxHops: 2})
+
+		assert.Equal(t, &model.HTTPStatusError{StatusCode: http.StatusFound}, execute(j))
+		assert.Len(t, j.Response.Redirects, 2)
+	})
+}
+
+func TestHTTPExecutor_proxy(t *testing.T) {
+	// the proxy answers the requests for partner.internal itself
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		credentials := base64.StdEncoding.EncodeToString([]byte("scheduler:secret"))
+		if r.Header.Get("Proxy-Authorization") != "Basic "+credentials {
+			w.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+
+		_, _ = w.Write([]byte("proxied " + r.URL.String()))
+	}))
+	defer proxy.Close()
+
+	j := &model.Job{
+		Type: model.JobTypeHTTP,
+		HTTPJob: &model.HTTPJob{
+			Method: "GET",
+			URL:    "http://partner.internal/hooks",
+			Auth:   model.Auth{Type: model.AuthTypeNone},
+			Proxy:  &model.ProxyConfig{URL: proxy.URL, Username: "scheduler", Password: "secret"},
+		},
+	}
+
+	executor, err := NewFactory(&http.Client{}).NewExecutor(j)
+	require.NoError(t, err)
+
+	assert.Nil(t, executor.Execute(context.Background(), j))
+	assert.Equal(t, "proxied http://partner.internal/hooks", j.Response.Body)
+
+	j.HTTPJob.Proxy.Password = "wrong"
+	assert.Equal(t, &model.HTTPStatusError{StatusCode: http.StatusProxyAuthRequired}, executor.Execute(context.Background(), j))
+}
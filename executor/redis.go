@@ -0,0 +1,161 @@
+package executor
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// redisScanCount is how many keys a DEL or EXPIRE job asks for with each SCAN.
+	redisScanCount = 1000
+
+	// redisCloseDelay is how long a client that is dropped from the cache stays open for the executions that
+	// are still using it.
+	redisCloseDelay = time.Minute
+)
+
+type redisExecutor struct {
+	clients *redisClientCache // the connection pools of the runner's Redis jobs
+}
+
+func (re *redisExecutor) Execute(ctx context.Context, j *model.Job) error {
+	redisJob := j.RedisJob
+
+	client, err := re.clients.Client(redisJob)
+	if err != nil {
+		return err
+	}
+
+	switch redisJob.Command {
+	case model.RedisCommandXAdd:
+		err = client.XAdd(ctx, &redis.XAddArgs{
+			Stream: redisJob.Stream,
+			MaxLen: redisJob.MaxLen,
+			Approx: true, // trimming whole macro nodes is much cheaper than trimming to the exact length
+			Values: redisJob.Fields,
+		}).Err()
+	case model.RedisCommandPublish:
+		err = client.Publish(ctx, redisJob.Channel, redisJob.Message).Err()
+	case model.RedisCommandDel:
+		err = scanRedisKeys(ctx, client, redisJob.Pattern, func(keys []string) error {
+			return client.Del(ctx, keys...).Err()
+		})
+	case model.RedisCommandExpire:
+		ttl := time.Duration(*redisJob.TTL)
+		err = scanRedisKeys(ctx, client, redisJob.Pattern, func(keys []string) error {
+			_, err := client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+				for _, key := range keys {
+					pipe.PExpire(ctx, key, ttl)
+				}
+				return nil
+			})
+			return err
+		})
+	default:
+		return fmt.Errorf("unknown Redis command: %v", redisJob.Command)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to run %s: %w", redisJob.Command, err)
+	}
+
+	return nil
+}
+
+// scanRedisKeys calls fn with the keys that match the pattern, a batch at a time. SCAN doesn't block the server
+// like KEYS does, but it can return a key more than once, so fn must be idempotent.
+func scanRedisKeys(ctx context.Context, client *redis.Client, pattern string, fn func(keys []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := client.Scan(ctx, cursor, pattern, redisScanCount).Result()
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+
+		if next == 0 {
+			return nil
+		}
+		cursor = next
+	}
+}
+
+// redisClientCache keeps a client, with its pool of connections, for each Redis server the runner's jobs
+// connect to, so the executions of the jobs with the same URL and TLS options share their connections. It is
+// shared by the executors of a runner.
+type redisClientCache struct {
+	mu      sync.Mutex
+	clients map[string]*redis.Client
+}
+
+func newRedisClientCache() *redisClientCache {
+	return &redisClientCache{clients: make(map[string]*redis.Client)}
+}
+
+// Client returns the client for the job's URL and TLS options. The files the TLS options refer to are read on
+// every call, so a rotated certificate gets a new client.
+func (rc *redisClientCache) Client(redisJob *model.RedisJob) (*redis.Client, error) {
+	config, err := loadTransportConfig(redisJob.TLS, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	key := redisClientKey(redisJob, config)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if client, ok := rc.clients[key]; ok {
+		return client, nil
+	}
+
+	options, err := redis.ParseURL(redisJob.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+
+	if redisJob.TLS != nil {
+		tlsConfig, err := config.tlsConfig(options.TLSConfig)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errTLSConfig, err)
+		}
+		options.TLSConfig = tlsConfig
+	}
+
+	if len(rc.clients) >= maxCachedClients {
+		for _, cached := range rc.clients {
+			cached := cached
+			time.AfterFunc(redisCloseDelay, func() { _ = cached.Close() })
+		}
+		rc.clients = make(map[string]*redis.Client)
+	}
+
+	client := redis.NewClient(options)
+	rc.clients[key] = client
+
+	return client, nil
+}
+
+// redisClientKey identifies the client of a job. The URL, which can hold a password, and the private key are
+// hashed, so they aren't kept around in the cache key.
+func redisClientKey(redisJob *model.RedisJob, config *transportConfig) string {
+	hash := sha256.New()
+	for _, part := range []string{redisJob.URL, config.key()} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,210 @@
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func executeRedis(t *testing.T, executor *redisExecutor, redisJob *model.RedisJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return executor.Execute(ctx, &model.Job{Type: model.JobTypeRedis, RedisJob: redisJob})
+}
+
+func TestRedisExecutor_XAdd(t *testing.T) {
+	server := miniredis.RunT(t)
+	executor := &redisExecutor{clients: newRedisClientCache()}
+
+	redisJob := &model.RedisJob{
+		URL:     "redis://" + server.Addr(),
+		Command: model.RedisCommandXAdd,
+		Stream:  "billing:invoices",
+		Fields:  map[string]string{"period": "monthly"},
+	}
+
+	require.NoError(t, executeRedis(t, executor, redisJob))
+	require.NoError(t, executeRedis(t, executor, redisJob))
+
+	entries, err := server.Stream("billing:invoices")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []string{"period", "monthly"}, entries[0].Values)
+
+	// the executions share the client's connection
+	assert.Equal(t, 1, server.TotalConnectionCount())
+}
+
+func TestRedisExecutor_Publish(t *testing.T) {
+	server := miniredis.RunT(t)
+	executor := &redisExecutor{clients: newRedisClientCache()}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	subscriber := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	defer subscriber.Close()
+
+	subscription := subscriber.Subscribe(ctx, "tariffs:updated")
+	defer subscription.Close()
+
+	_, err := subscription.Receive(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, executeRedis(t, executor, &model.RedisJob{
+		URL:     "redis://" + server.Addr(),
+		Command: model.RedisCommandPublish,
+		Channel: "tariffs:updated",
+		Message: `{"tariff": "night"}`,
+	}))
+
+	msg, err := subscription.ReceiveMessage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "tariffs:updated", msg.Channel)
+	assert.Equal(t, `{"tariff": "night"}`, msg.Payload)
+}
+
+func TestRedisExecutor_keys(t *testing.T) {
+	ttl := model.Duration(time.Hour)
+
+	tests := []struct {
+		name      string
+		command   model.RedisCommand
+		keys      int
+		ttl       *model.Duration
+		wantTTL   time.Duration
+		wantExist bool
+	}{
+		{
+			name:    "DEL",
+			command: model.RedisCommandDel,
+			// miniredis's SCAN cursor is an offset into the sorted keys, so unlike Redis it skips keys when the
+			// keys it already returned are deleted: the keys fit in a single SCAN
+			keys:      redisScanCount,
+			wantExist: false,
+		},
+		{
+			name:      "EXPIRE",
+			command:   model.RedisCommandExpire,
+			ttl:       &ttl,
+			keys:      2*redisScanCount + 1, // more keys than a single SCAN returns
+			wantTTL:   time.Hour,
+			wantExist: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := miniredis.RunT(t)
+			executor := &redisExecutor{clients: newRedisClientCache()}
+
+			for i := 0; i < tt.keys; i++ {
+				require.NoError(t, server.Set(fmt.Sprintf("cache:tariffs:%d", i), "night"))
+			}
+			require.NoError(t, server.Set("cache:chargers:1", "CP-1042"))
+
+			require.NoError(t, executeRedis(t, executor, &model.RedisJob{
+				URL:     "redis://" + server.Addr(),
+				Command: tt.command,
+				Pattern: "cache:tariffs:*",
+				TTL:     tt.ttl,
+			}))
+
+			for i := 0; i < tt.keys; i++ {
+				key := fmt.Sprintf("cache:tariffs:%d", i)
+				require.Equal(t, tt.wantExist, server.Exists(key), key)
+				require.Equal(t, tt.wantTTL, server.TTL(key), key)
+			}
+
+			// the keys that don't match the pattern are left as they are
+			assert.True(t, server.Exists("cache:chargers:1"))
+			assert.Zero(t, server.TTL("cache:chargers:1"))
+		})
+	}
+}
+
+func TestRedisExecutor_credentials(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.RequireUserAuth("scheduler", "s3cr3t")
+
+	executor := &redisExecutor{clients: newRedisClientCache()}
+
+	newJob := func(password string) *model.RedisJob {
+		return &model.RedisJob{
+			URL:     fmt.Sprintf("redis://scheduler:%s@%s/0", password, server.Addr()),
+			Command: model.RedisCommandPublish,
+			Channel: "tariffs:updated",
+		}
+	}
+
+	t.Run("valid credentials", func(t *testing.T) {
+		require.NoError(t, executeRedis(t, executor, newJob("s3cr3t")))
+	})
+
+	t.Run("invalid credentials", func(t *testing.T) {
+		err := executeRedis(t, executor, newJob("wrong"))
+		assert.ErrorContains(t, err, "failed to run PUBLISH")
+		assert.ErrorContains(t, err, "WRONGPASS")
+	})
+}
+
+func TestRedisExecutor_TLS(t *testing.T) {
+	ca := newTestCA(t)
+
+	certPEM, keyPEM := ca.issue(t, "redis.internal")
+	certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+
+	server := miniredis.NewMiniRedis()
+	require.NoError(t, server.StartTLS(&tls.Config{Certificates: []tls.Certificate{certificate}}))
+	t.Cleanup(server.Close)
+
+	executor := &redisExecutor{clients: newRedisClientCache()}
+
+	newJob := func(tlsConfig *model.TLSConfig) *model.RedisJob {
+		return &model.RedisJob{
+			URL:     "rediss://" + server.Addr(),
+			Command: model.RedisCommandPublish,
+			Channel: "tariffs:updated",
+			TLS:     tlsConfig,
+		}
+	}
+
+	t.Run("trusted CA", func(t *testing.T) {
+		require.NoError(t, executeRedis(t, executor, newJob(&model.TLSConfig{CACert: ca.pem, ServerName: "redis.internal"})))
+	})
+
+	t.Run("unknown CA", func(t *testing.T) {
+		err := executeRedis(t, executor, newJob(&model.TLSConfig{ServerName: "redis.internal"}))
+		assert.ErrorContains(t, err, "certificate signed by unknown authority")
+	})
+}
+
+func TestRedisClientCache_Client(t *testing.T) {
+	cache := newRedisClientCache()
+
+	newJob := func(url string) *model.RedisJob {
+		return &model.RedisJob{URL: url, Command: model.RedisCommandPublish, Channel: "tariffs:updated"}
+	}
+
+	first, err := cache.Client(newJob("redis://redis.internal:6379/0"))
+	require.NoError(t, err)
+
+	second, err := cache.Client(newJob("redis://redis.internal:6379/0"))
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	other, err := cache.Client(newJob("redis://redis.internal:6379/1"))
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+	assert.Equal(t, 1, other.Options().DB)
+}
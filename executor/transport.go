@@ -0,0 +1,228 @@
+package executor
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+)
+
+// maxCachedClients bounds the number of transport configurations the clients are cached for. When it is reached
+// the cache is emptied, e.g. after many certificate rotations, and the clients are built again when needed.
+const maxCachedClients = 256
+
+var (
+	errTLSConfig   = errors.New("failed to configure TLS")
+	errProxyConfig = errors.New("failed to configure proxy")
+)
+
+// clientCache builds the HTTP clients of the jobs with their own TLS or proxy configuration, each with its own
+// transport, and caches them by configuration. It is shared by the executors of a runner, so jobs with the same
+// configuration share their connections.
+type clientCache struct {
+	base *http.Client // the clients are copies of the base client with another transport
+
+	mu      sync.Mutex
+	clients map[string]*http.Client
+}
+
+func newClientCache(client HttpClient) *clientCache {
+	base, ok := client.(*http.Client)
+	if !ok {
+		base = &http.Client{}
+	}
+
+	return &clientCache{
+		base:    base,
+		clients: make(map[string]*http.Client),
+	}
+}
+
+// transportConfig is the TLS and proxy configuration of a transport, with the files it refers to read.
+type transportConfig struct {
+	clientCert []byte
+	clientKey  []byte
+	caCert     []byte
+	serverName string
+	minVersion uint16
+	proxyURL   *url.URL
+}
+
+// Client returns the client for the TLS and proxy configuration, either of which can be nil, and the key of the
+// configuration. The files the TLS configuration refers to are read on every call, so a rotated certificate gets
+// a new client.
+func (cc *clientCache) Client(tlsConfig *model.TLSConfig, proxy *model.ProxyConfig) (HttpClient, string, error) {
+	config, err := loadTransportConfig(tlsConfig, proxy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := config.key()
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if client, ok := cc.clients[key]; ok {
+		return client, key, nil
+	}
+
+	client, err := cc.build(config)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: %w", errTLSConfig, err)
+	}
+
+	if len(cc.clients) >= maxCachedClients {
+		for _, cached := range cc.clients {
+			cached.CloseIdleConnections()
+		}
+		cc.clients = make(map[string]*http.Client)
+	}
+
+	cc.clients[key] = client
+
+	return client, key, nil
+}
+
+// build returns a copy of the base client with a transport that uses the configuration.
+func (cc *clientCache) build(config *transportConfig) (*http.Client, error) {
+	transport, ok := cc.base.Transport.(*http.Transport)
+	if !ok {
+		transport = http.DefaultTransport.(*http.Transport)
+	}
+	transport = transport.Clone()
+
+	tlsConfig, err := config.tlsConfig(transport.TLSClientConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	transport.TLSClientConfig = tlsConfig
+
+	if config.proxyURL != nil {
+		transport.Proxy = http.ProxyURL(config.proxyURL)
+	}
+
+	client := *cc.base
+	client.Transport = transport
+
+	return &client, nil
+}
+
+// tlsConfig returns a copy of the base TLS configuration, which can be nil, with the configuration's TLS options.
+func (c *transportConfig) tlsConfig(base *tls.Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+	if base != nil {
+		tlsConfig = base.Clone()
+	}
+
+	if c.clientCert != nil {
+		certificate, err := tls.X509KeyPair(c.clientCert, c.clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if c.caCert != nil {
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(c.caCert) {
+			return nil, errors.New("CA bundle has no valid PEM encoded certificates")
+		}
+		tlsConfig.RootCAs = roots
+	}
+
+	if c.serverName != "" {
+		tlsConfig.ServerName = c.serverName
+	}
+
+	if c.minVersion != 0 {
+		tlsConfig.MinVersion = c.minVersion
+	}
+
+	return tlsConfig, nil
+}
+
+// loadTLSConfig returns the TLS configuration of a connection that isn't made by an HTTP client, e.g. to a gRPC
+// server or a Kafka broker.
+func loadTLSConfig(tlsConfig *model.TLSConfig) (*tls.Config, error) {
+	config, err := loadTransportConfig(tlsConfig, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded, err := config.tlsConfig(nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errTLSConfig, err)
+	}
+
+	return loaded, nil
+}
+
+func loadTransportConfig(tlsConfig *model.TLSConfig, proxy *model.ProxyConfig) (*transportConfig, error) {
+	config := &transportConfig{}
+
+	if tlsConfig != nil {
+		config.serverName = tlsConfig.ServerName
+		config.minVersion = tlsConfig.Version()
+
+		var err error
+		if config.clientCert, err = pemOrFile(tlsConfig.ClientCert, tlsConfig.ClientCertFile); err != nil {
+			return nil, fmt.Errorf("%w: %w", errTLSConfig, err)
+		}
+
+		if config.clientKey, err = pemOrFile(tlsConfig.ClientKey, tlsConfig.ClientKeyFile); err != nil {
+			return nil, fmt.Errorf("%w: %w", errTLSConfig, err)
+		}
+
+		if config.caCert, err = pemOrFile(tlsConfig.CACert, tlsConfig.CACertFile); err != nil {
+			return nil, fmt.Errorf("%w: %w", errTLSConfig, err)
+		}
+	}
+
+	if proxy != nil {
+		proxyURL, err := proxy.ProxyURL()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errProxyConfig, err)
+		}
+		config.proxyURL = proxyURL
+	}
+
+	return config, nil
+}
+
+// pemOrFile returns the PEM encoded data, or the contents of the file if the data isn't set. It returns nil if neither is set.
+func pemOrFile(data, path string) ([]byte, error) {
+	if data != "" {
+		return []byte(data), nil
+	}
+
+	if path == "" {
+		return nil, nil
+	}
+
+	return os.ReadFile(path)
+}
+
+// key identifies the configuration. The private key and the proxy's password are hashed, so they aren't kept around in the cache key.
+func (c *transportConfig) key() string {
+	var proxyURL string
+	if c.proxyURL != nil {
+		proxyURL = c.proxyURL.String()
+	}
+
+	hash := sha256.New()
+	for _, part := range [][]byte{c.clientCert, c.clientKey, c.caCert, []byte(c.serverName), {byte(c.minVersion >> 8), byte(c.minVersion)}, []byte(proxyURL)} {
+		hash.Write(part)
+		hash.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -0,0 +1,222 @@
+package executor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testCA issues certificates for the TLS tests.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  string
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key, pem: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))}
+}
+
+// issue returns a PEM encoded certificate and key for the DNS name, for servers, or for clients if name is empty.
+func (ca *testCA) issue(t *testing.T, name string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "scheduler"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if name != "" {
+		template.DNSNames = []string{name}
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+// newMutualTLSServer returns a server for partner.internal whose certificate is issued by the CA, and that
+// requires a client certificate issued by the CA.
+func newMutualTLSServer(t *testing.T, ca *testCA) *httptest.Server {
+	certPEM, keyPEM := ca.issue(t, "partner.internal")
+	certificate, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0) // the failed handshakes are expected
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MaxVersion:   tls.VersionTLS12,
+	}
+	server.StartTLS()
+
+	return server
+}
+
+func TestHTTPExecutor_mutualTLS(t *testing.T) {
+	ctx := context.Background()
+
+	ca := newTestCA(t)
+	server := newMutualTLSServer(t, ca)
+	defer server.Close()
+
+	clientCert, clientKey := ca.issue(t, "")
+
+	newJob := func(tlsConfig *model.TLSConfig) *model.Job {
+		return &model.Job{
+			Type: model.JobTypeHTTP,
+			HTTPJob: &model.HTTPJob{
+				Method: "GET",
+				URL:    server.URL,
+				Auth:   model.Auth{Type: model.AuthTypeNone},
+				TLS:    tlsConfig,
+			},
+		}
+	}
+
+	factory := NewFactory(&http.Client{Timeout: 5 * time.Second})
+
+	execute := func(j *model.Job) error {
+		executor, err := factory.NewExecutor(j)
+		require.NoError(t, err)
+
+		return executor.Execute(ctx, j)
+	}
+
+	t.Run("client certificate and private CA", func(t *testing.T) {
+		assert.Nil(t, execute(newJob(&model.TLSConfig{
+			ClientCert: clientCert,
+			ClientKey:  clientKey,
+			CACert:     ca.pem,
+			ServerName: "partner.internal",
+		})))
+	})
+
+	t.Run("server certificate isn't trusted without the CA", func(t *testing.T) {
+		err := execute(newJob(&model.TLSConfig{ClientCert: clientCert, ClientKey: clientKey, ServerName: "partner.internal"}))
+		assert.ErrorContains(t, err, "certificate signed by unknown authority")
+	})
+
+	t.Run("server certificate is verified for the URL's host without a server name", func(t *testing.T) {
+		err := execute(newJob(&model.TLSConfig{ClientCert: clientCert, ClientKey: clientKey, CACert: ca.pem}))
+		assert.ErrorContains(t, err, "cannot validate certificate for 127.0.0.1")
+	})
+
+	t.Run("server requires a client certificate", func(t *testing.T) {
+		assert.NotNil(t, execute(newJob(&model.TLSConfig{CACert: ca.pem, ServerName: "partner.internal"})))
+	})
+
+	t.Run("minimum version", func(t *testing.T) {
+		err := execute(newJob(&model.TLSConfig{
+			ClientCert: clientCert,
+			ClientKey:  clientKey,
+			CACert:     ca.pem,
+			ServerName: "partner.internal",
+			MinVersion: "1.3",
+		}))
+		assert.ErrorContains(t, err, "protocol version")
+	})
+
+	t.Run("certificates from files", func(t *testing.T) {
+		dir := t.TempDir()
+		for name, data := range map[string]string{"client.crt": clientCert, "client.key": clientKey, "ca.pem": ca.pem} {
+			require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600))
+		}
+
+		assert.Nil(t, execute(newJob(&model.TLSConfig{
+			ClientCertFile: filepath.Join(dir, "client.crt"),
+			ClientKeyFile:  filepath.Join(dir, "client.key"),
+			CACertFile:     filepath.Join(dir, "ca.pem"),
+			ServerName:     "partner.internal",
+		})))
+
+		err := execute(newJob(&model.TLSConfig{
+			ClientCertFile: filepath.Join(dir, "missing.crt"),
+			ClientKeyFile:  filepath.Join(dir, "client.key"),
+		}))
+		assert.ErrorIs(t, err, errTLSConfig)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestClientCache(t *testing.T) {
+	ca := newTestCA(t)
+	clientCert, clientKey := ca.issue(t, "")
+
+	base := &http.Client{Timeout: 5 * time.Second}
+	cache := newClientCache(base)
+
+	config := &model.TLSConfig{ClientCert: clientCert, ClientKey: clientKey, CACert: ca.pem}
+
+	// the clients of the same configuration are shared
+	first, _, err := cache.Client(config, nil)
+	require.NoError(t, err)
+
+	second, _, err := cache.Client(&model.TLSConfig{ClientCert: clientCert, ClientKey: clientKey, CACert: ca.pem}, nil)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+
+	// the clients are copies of the base client with their own transport
+	client := first.(*http.Client)
+	assert.Equal(t, base.Timeout, client.Timeout)
+	assert.NotSame(t, http.DefaultTransport, client.Transport)
+	assert.Len(t, client.Transport.(*http.Transport).TLSClientConfig.Certificates, 1)
+
+	// another configuration has its own client
+	other, _, err := cache.Client(&model.TLSConfig{CACert: ca.pem, MinVersion: "1.3"}, nil)
+	require.NoError(t, err)
+	assert.NotSame(t, first, other)
+	assert.Equal(t, uint16(tls.VersionTLS13), other.(*http.Client).Transport.(*http.Transport).TLSClientConfig.MinVersion)
+}
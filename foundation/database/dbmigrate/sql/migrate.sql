@@ -70,4 +70,225 @@ CREATE INDEX job_executions_start_time_index ON job_executions (start_time);
 -- Version: 1.02
 -- Description: Add tags column to jobs table
 
-ALTER TABLE jobs ADD tags TEXT[];
\ No newline at end of file
+ALTER TABLE jobs ADD tags TEXT[];
+
+-- Version: 1.03
+-- Description: Add retry policy column to jobs table
+
+ALTER TABLE jobs ADD retry_policy JSONB;
+
+-- Version: 1.04
+-- Description: Persist retry state of job runs and link executions to their run
+
+ALTER TABLE jobs ADD run_id uuid;
+ALTER TABLE jobs ADD attempt INT NOT NULL DEFAULT 0;
+ALTER TABLE jobs ADD retry_at TIMESTAMPTZ;
+
+CREATE INDEX retry_at_index ON jobs (retry_at);
+
+ALTER TABLE job_executions ADD run_id uuid;
+ALTER TABLE job_executions ADD attempt INT NOT NULL DEFAULT 1;
+
+CREATE INDEX job_executions_run_id_index ON job_executions (run_id);
+
+-- Version: 1.05
+-- Description: Add per-job execution timeout and the TIMED_OUT execution status
+
+ALTER TYPE job_execution_status_enum ADD VALUE 'TIMED_OUT';
+
+ALTER TABLE jobs ADD timeout_ms BIGINT;
+
+-- Version: 1.06
+-- Description: Add fencing token to job claims
+
+ALTER TABLE jobs ADD fencing_token BIGINT NOT NULL DEFAULT 0;
+
+-- Version: 1.07
+-- Description: Add manual job triggers
+
+CREATE TYPE run_trigger_enum AS ENUM ('scheduled', 'manual');
+
+ALTER TABLE jobs ADD run_trigger run_trigger_enum NOT NULL DEFAULT 'scheduled';
+ALTER TABLE jobs ADD triggered_at TIMESTAMPTZ;
+
+CREATE INDEX triggered_at_index ON jobs (triggered_at);
+
+ALTER TABLE job_executions ADD run_trigger run_trigger_enum NOT NULL DEFAULT 'scheduled';
+
+-- Version: 1.08
+-- Description: Add misfire policy of recurring jobs and the MISSED execution status
+
+ALTER TYPE job_execution_status_enum ADD VALUE 'MISSED';
+
+CREATE TYPE misfire_policy_enum AS ENUM ('fire_once_now', 'fire_all_missed', 'skip_to_next');
+
+ALTER TABLE jobs ADD misfire_policy misfire_policy_enum NOT NULL DEFAULT 'fire_once_now';
+ALTER TABLE jobs ADD misfire_threshold_ms BIGINT;
+
+-- Version: 1.09
+-- Description: Add time zone of cron schedules
+
+ALTER TABLE jobs ADD timezone VARCHAR(64);
+
+-- Version: 1.10
+-- Description: Add concurrency policy of recurring jobs and the SKIPPED execution status (existing jobs get the Allow policy, so their runs keep overlapping)
+
+ALTER TYPE job_execution_status_enum ADD VALUE 'SKIPPED';
+
+CREATE TYPE concurrency_policy_enum AS ENUM ('Allow', 'Forbid', 'Replace');
+
+ALTER TABLE jobs ADD concurrency_policy concurrency_policy_enum NOT NULL DEFAULT 'Allow';
+ALTER TABLE jobs ADD claimed_at TIMESTAMPTZ;
+
+-- Version: 1.11
+-- Description: Add job priorities
+
+ALTER TABLE jobs ADD priority INT NOT NULL DEFAULT 0;
+
+-- Version: 1.12
+-- Description: Add runner pools of jobs
+
+ALTER TABLE jobs ADD pool VARCHAR(64);
+
+-- Version: 1.13
+-- Description: Add workflows of jobs and their runs
+
+CREATE TABLE workflows (
+    id uuid PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    nodes JSONB NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TYPE workflow_run_status_enum AS ENUM (
+    'RUNNING',
+    'SUCCEEDED',
+    'FAILED'
+);
+
+CREATE TABLE workflow_runs (
+    id uuid PRIMARY KEY,
+    workflow_id uuid NOT NULL REFERENCES workflows (id) ON DELETE CASCADE,
+    status workflow_run_status_enum NOT NULL DEFAULT 'RUNNING',
+    nodes JSONB NOT NULL,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX workflow_runs_workflow_id_index ON workflow_runs (workflow_id, created_at);
+
+ALTER TYPE run_trigger_enum ADD VALUE 'workflow';
+
+ALTER TABLE jobs ADD workflow_run_id uuid;
+ALTER TABLE jobs ADD triggered_workflow_run_id uuid;
+
+-- Version: 1.14
+-- Description: Record when a job's run was due and the ID of each execution, which are passed to payload templates
+ALTER TABLE jobs ADD scheduled_at TIMESTAMPTZ;
+
+ALTER TABLE job_executions ADD execution_id uuid;
+
+-- Version: 1.15
+-- Description: Record the response to the executions of HTTP jobs
+ALTER TABLE job_executions ADD response_status_code INT;
+ALTER TABLE job_executions ADD response_headers JSONB;
+ALTER TABLE job_executions ADD response_body TEXT;
+ALTER TABLE job_executions ADD response_body_truncated BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Version: 1.16
+-- Description: Record the redirects that were followed by the executions of HTTP jobs
+ALTER TABLE job_executions ADD response_redirects JSONB;
+
+-- Version: 1.17
+-- Description: Add the GRPC job type (the new enum value can only be used once this migration is committed)
+ALTER TYPE job_type_enum ADD VALUE 'GRPC';
+
+-- Version: 1.18
+-- Description: Add the grpc_job column and only allow the fields of a job's type to be set
+ALTER TABLE jobs ADD grpc_job JSONB;
+
+ALTER TABLE jobs DROP CONSTRAINT check_job_type;
+ALTER TABLE jobs ADD CONSTRAINT
+    check_job_type CHECK (
+        (type = 'HTTP') = (http_job IS NOT NULL) AND
+        (type = 'AMQP') = (amqp_job IS NOT NULL) AND
+        (type = 'GRPC') = (grpc_job IS NOT NULL)
+    );
+
+-- Version: 1.19
+-- Description: Add the KAFKA job type (the new enum value can only be used once this migration is committed)
+ALTER TYPE job_type_enum ADD VALUE 'KAFKA';
+
+-- Version: 1.20
+-- Description: Add the kafka_job column
+ALTER TABLE jobs ADD kafka_job JSONB;
+
+ALTER TABLE jobs DROP CONSTRAINT check_job_type;
+ALTER TABLE jobs ADD CONSTRAINT
+    check_job_type CHECK (
+        (type = 'HTTP') = (http_job IS NOT NULL) AND
+        (type = 'AMQP') = (amqp_job IS NOT NULL) AND
+        (type = 'GRPC') = (grpc_job IS NOT NULL) AND
+        (type = 'KAFKA') = (kafka_job IS NOT NULL)
+    );
+
+-- Version: 1.21
+-- Description: Add the MQTT job type (the new enum value can only be used once this migration is committed)
+ALTER TYPE job_type_enum ADD VALUE 'MQTT';
+
+-- Version: 1.22
+-- Description: Add the mqtt_job column
+ALTER TABLE jobs ADD mqtt_job JSONB;
+
+ALTER TABLE jobs DROP CONSTRAINT check_job_type;
+ALTER TABLE jobs ADD CONSTRAINT
+    check_job_type CHECK (
+        (type = 'HTTP') = (http_job IS NOT NULL) AND
+        (type = 'AMQP') = (amqp_job IS NOT NULL) AND
+        (type = 'GRPC') = (grpc_job IS NOT NULL) AND
+        (type = 'KAFKA') = (kafka_job IS NOT NULL) AND
+        (type = 'MQTT') = (mqtt_job IS NOT NULL)
+    );
+
+-- Version: 1.23
+-- Description: Add the NATS job type (the new enum value can only be used once this migration is committed)
+ALTER TYPE job_type_enum ADD VALUE 'NATS';
+
+-- Version: 1.24
+-- Description: Add the nats_job column
+ALTER TABLE jobs ADD nats_job JSONB;
+
+ALTER TABLE jobs DROP CONSTRAINT check_job_type;
+ALTER TABLE jobs ADD CONSTRAINT
+    check_job_type CHECK (
+        (type = 'HTTP') = (http_job IS NOT NULL) AND
+        (type = 'AMQP') = (amqp_job IS NOT NULL) AND
+        (type = 'GRPC') = (grpc_job IS NOT NULL) AND
+        (type = 'KAFKA') = (kafka_job IS NOT NULL) AND
+        (type = 'MQTT') = (mqtt_job IS NOT NULL) AND
+        (type = 'NATS') = (nats_job IS NOT NULL)
+    );
+
+-- Version: 1.25
+-- Description: Add the REDIS job type (the new enum value can only be used once this migration is committed)
+ALTER TYPE job_type_enum ADD VALUE 'REDIS';
+
+-- Version: 1.26
+-- Description: Add the redis_job column
+ALTER TABLE jobs ADD redis_job JSONB;
+
+ALTER TABLE jobs DROP CONSTRAINT check_job_type;
+ALTER TABLE jobs ADD CONSTRAINT
+    check_job_type CHECK (
+        (type = 'HTTP') = (http_job IS NOT NULL) AND
+        (type = 'AMQP') = (amqp_job IS NOT NULL) AND
+        (type = 'GRPC') = (grpc_job IS NOT NULL) AND
+        (type = 'KAFKA') = (kafka_job IS NOT NULL) AND
+        (type = 'MQTT') = (mqtt_job IS NOT NULL) AND
+        (type = 'NATS') = (nats_job IS NOT NULL) AND
+        (type = 'REDIS') = (redis_job IS NOT NULL)
+    );
\ No newline at end of file
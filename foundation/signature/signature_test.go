@@ -0,0 +1,107 @@
+package signature
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompute(t *testing.T) {
+	mac, err := Compute(SHA256, []byte("secret"), 1700000000, "POST", "/hooks?a=1", []byte(`{"hello":"world"}`))
+	assert.Nil(t, err)
+	assert.Len(t, mac, 64)
+
+	// every signed part changes the signature
+	for _, other := range []func() (string, error){
+		func() (string, error) {
+			return Compute(SHA256, []byte("other"), 1700000000, "POST", "/hooks?a=1", []byte(`{"hello":"world"}`))
+		},
+		func() (string, error) {
+			return Compute(SHA256, []byte("secret"), 1700000001, "POST", "/hooks?a=1", []byte(`{"hello":"world"}`))
+		},
+		func() (string, error) {
+			return Compute(SHA256, []byte("secret"), 1700000000, "PUT", "/hooks?a=1", []byte(`{"hello":"world"}`))
+		},
+		func() (string, error) {
+			return Compute(SHA256, []byte("secret"), 1700000000, "POST", "/hooks?a=2", []byte(`{"hello":"world"}`))
+		},
+		func() (string, error) {
+			return Compute(SHA256, []byte("secret"), 1700000000, "POST", "/hooks?a=1", []byte(`{"hello":"there"}`))
+		},
+	} {
+		otherMAC, err := other()
+		assert.Nil(t, err)
+		assert.NotEqual(t, mac, otherMAC)
+	}
+
+	mac, err = Compute(SHA512, []byte("secret"), 1700000000, "POST", "/hooks", nil)
+	assert.Nil(t, err)
+	assert.Len(t, mac, 128)
+
+	_, err = Compute("md5", []byte("secret"), 1700000000, "POST", "/hooks", nil)
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+}
+
+func TestVerify(t *testing.T) {
+	signedAt := time.Unix(1700000000, 0)
+
+	newRequest := func(t *testing.T, body string) *http.Request {
+		req := httptest.NewRequest("POST", "https://example.com/hooks?a=1", strings.NewReader(body))
+		assert.Nil(t, Sign(req, SHA256, []byte("secret"), "", signedAt))
+
+		return req
+	}
+
+	t.Run("valid signature", func(t *testing.T) {
+		req := newRequest(t, `{"hello":"world"}`)
+		assert.True(t, strings.HasPrefix(req.Header.Get(DefaultHeader), "sha256="))
+		assert.Equal(t, "1700000000", req.Header.Get(TimestampHeader))
+
+		v := &Verifier{Secret: []byte("secret"), now: func() time.Time { return signedAt.Add(time.Minute) }}
+		assert.Nil(t, v.Verify(req))
+
+		// the body is restored
+		body, _ := io.ReadAll(req.Body)
+		assert.Equal(t, `{"hello":"world"}`, string(body))
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		v := &Verifier{Secret: []byte("other"), now: func() time.Time { return signedAt }}
+		assert.Equal(t, ErrInvalidSignature, v.Verify(newRequest(t, `{"hello":"world"}`)))
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		req := newRequest(t, `{"hello":"world"}`)
+		req.Body = io.NopCloser(strings.NewReader(`{"hello":"there"}`))
+
+		v := &Verifier{Secret: []byte("secret"), now: func() time.Time { return signedAt }}
+		assert.Equal(t, ErrInvalidSignature, v.Verify(req))
+	})
+
+	t.Run("replayed request", func(t *testing.T) {
+		v := &Verifier{Secret: []byte("secret"), now: func() time.Time { return signedAt.Add(DefaultTolerance + time.Second) }}
+		assert.Equal(t, ErrInvalidTimestamp, v.Verify(newRequest(t, `{"hello":"world"}`)))
+
+		v.Tolerance = time.Hour
+		assert.Nil(t, v.Verify(newRequest(t, `{"hello":"world"}`)))
+	})
+
+	t.Run("unsigned request", func(t *testing.T) {
+		v := &Verifier{Secret: []byte("secret"), now: func() time.Time { return signedAt }}
+		assert.Equal(t, ErrMissingSignature, v.Verify(httptest.NewRequest("GET", "https://example.com/hooks", nil)))
+	})
+
+	t.Run("custom header", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "https://example.com/hooks", nil)
+		assert.Nil(t, Sign(req, SHA512, []byte("secret"), "X-Signature", signedAt))
+		assert.True(t, strings.HasPrefix(req.Header.Get("X-Signature"), "sha512="))
+
+		v := &Verifier{Secret: []byte("secret"), Header: "X-Signature", now: func() time.Time { return signedAt }}
+		assert.Nil(t, v.Verify(req))
+	})
+}
@@ -1,6 +1,6 @@
 // @title           Scheduler management API
 // @version         1.0
-// @description     This is scheduler management API server. Use this API to manage jobs.
+// @description     This is scheduler management API server. Use this API to manage jobs and workflows.
 
 // @host      http://localhost:8000
 // @BasePath  /v1
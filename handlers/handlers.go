@@ -11,6 +11,7 @@ import (
 
 	"github.com/GLCharge/distributed-scheduler/foundation/database"
 	"github.com/GLCharge/distributed-scheduler/service/job"
+	"github.com/GLCharge/distributed-scheduler/service/workflow"
 	"github.com/GLCharge/distributed-scheduler/store/postgres"
 	"github.com/gin-gonic/gin"
 	"github.com/jmoiron/sqlx"
@@ -61,6 +62,18 @@ func APIMux(cfg APIMuxConfig) http.Handler {
 	// Define a group of routes for the jobs endpoint
 	JobsRoutesV1(router, jobsHandler)
 
+	// ==================
+	// Workflows
+
+	// Create a new workflow service with the job store (workflows are stored alongside their jobs)
+	workflowService := workflow.NewService(jobStore, cfg.Log)
+
+	// Create a new workflows handler with the workflow service
+	workflowsHandler := NewWorkflowsHandler(workflowService)
+
+	// Define a group of routes for the workflows endpoint
+	WorkflowsRoutesV1(router, workflowsHandler)
+
 	// Return the router as a http.Handler
 	return router
 }
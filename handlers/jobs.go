@@ -19,6 +19,11 @@ func JobsRoutesV1(router *gin.Engine, jobsHandler *Jobs) {
 		jobsRouter.DELETE("/:id", jobsHandler.DeleteJob())
 		jobsRouter.GET("", jobsHandler.ListJobs())
 		jobsRouter.GET("/:id/executions", jobsHandler.GetJobExecutions())
+		jobsRouter.POST("/:id/pause", jobsHandler.PauseJob())
+		jobsRouter.POST("/:id/resume", jobsHandler.ResumeJob())
+		jobsRouter.POST("/:id/trigger", jobsHandler.TriggerJob())
+		jobsRouter.POST("/pause", jobsHandler.PauseJobsByTags())
+		jobsRouter.POST("/resume", jobsHandler.ResumeJobsByTags())
 	}
 }
 
@@ -206,7 +211,7 @@ func (j *Jobs) ListJobs() gin.HandlerFunc {
 
 // GetJobExecutions godoc
 // @Summary Get job executions
-// @Description Get job executions with the given job ID, failed only flag, limit and offset
+// @Description Get job executions with the given job ID, failed only flag, limit and offset. Executions of HTTP jobs include the response that was received and the redirects that were followed
 // @Tags jobs
 // @Accept json
 // @Produce json
@@ -246,6 +251,166 @@ func (j *Jobs) GetJobExecutions() gin.HandlerFunc {
 	}
 }
 
+// PauseJob godoc
+// @Summary Pause a job
+// @Description Pause the job with the given job ID, so it is not run until it is resumed
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/pause [post]
+func (j *Jobs) PauseJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		job, err := j.service.PauseJob(ctx.Request.Context(), id)
+		if err != nil {
+			jobErr := model.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, job)
+	}
+}
+
+// ResumeJob godoc
+// @Summary Resume a job
+// @Description Resume the paused job with the given job ID. The next run of a recurring job is computed from its cron schedule, missed runs are skipped
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 200 {object} model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/resume [post]
+func (j *Jobs) ResumeJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		job, err := j.service.ResumeJob(ctx.Request.Context(), id)
+		if err != nil {
+			jobErr := model.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, job)
+	}
+}
+
+// TriggerJob godoc
+// @Summary Trigger a job
+// @Description Run the job with the given job ID now, without changing its schedule. The run is picked up by a runner and recorded with the manual trigger
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param id path string true "Job ID"
+// @Success 202 {object} model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/{id}/trigger [post]
+func (j *Jobs) TriggerJob() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		job, err := j.service.TriggerJob(ctx.Request.Context(), id)
+		if err != nil {
+			jobErr := model.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, job)
+	}
+}
+
+// PauseJobsByTags godoc
+// @Summary Pause jobs by tags
+// @Description Pause all jobs that have all of the given tags
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param selector body model.JobTagSelector true "Job Tag Selector"
+// @Success 200 {object} []model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/pause [post]
+func (j *Jobs) PauseJobsByTags() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		selector := model.JobTagSelector{}
+		if err := ctx.BindJSON(&selector); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		jobs, err := j.service.PauseJobsByTags(ctx.Request.Context(), selector.Tags)
+		if err != nil {
+			jobErr := model.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, jobs)
+	}
+}
+
+// ResumeJobsByTags godoc
+// @Summary Resume jobs by tags
+// @Description Resume all jobs that have all of the given tags. The next run of recurring jobs is computed from their cron schedule, missed runs are skipped
+// @Tags jobs
+// @Accept json
+// @Produce json
+// @Param selector body model.JobTagSelector true "Job Tag Selector"
+// @Success 200 {object} []model.Job
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /jobs/resume [post]
+func (j *Jobs) ResumeJobsByTags() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		selector := model.JobTagSelector{}
+		if err := ctx.BindJSON(&selector); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		jobs, err := j.service.ResumeJobsByTags(ctx.Request.Context(), selector.Tags)
+		if err != nil {
+			jobErr := model.ToCustomJobError(err)
+
+			ctx.JSON(jobErr.Code, ErrorResponse{Error: jobErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, jobs)
+	}
+}
+
 func LimitAndOffset(ctx *gin.Context) (uint64, uint64) {
 	limitStr := ctx.Query("limit")
 	offsetStr := ctx.Query("offset")
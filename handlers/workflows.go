@@ -0,0 +1,262 @@
+package handlers
+
+import (
+	"github.com/google/uuid"
+	"net/http"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	workflowService "github.com/GLCharge/distributed-scheduler/service/workflow"
+	"github.com/gin-gonic/gin"
+)
+
+func WorkflowsRoutesV1(router *gin.Engine, workflowsHandler *Workflows) {
+	workflowsRouter := router.Group("/v1/workflows")
+	{
+		workflowsRouter.POST("", workflowsHandler.CreateWorkflow())
+		workflowsRouter.GET("/:id", workflowsHandler.GetWorkflow())
+		workflowsRouter.DELETE("/:id", workflowsHandler.DeleteWorkflow())
+		workflowsRouter.GET("", workflowsHandler.ListWorkflows())
+		workflowsRouter.POST("/:id/trigger", workflowsHandler.TriggerWorkflow())
+		workflowsRouter.GET("/:id/runs", workflowsHandler.ListWorkflowRuns())
+		workflowsRouter.GET("/:id/runs/:runId", workflowsHandler.GetWorkflowRun())
+	}
+}
+
+func NewWorkflowsHandler(service *workflowService.Service) *Workflows {
+	return &Workflows{
+		service: service,
+	}
+}
+
+type Workflows struct {
+	service *workflowService.Service
+}
+
+// CreateWorkflow godoc
+// @Summary Create a workflow
+// @Description Create a workflow of existing jobs with the given workflow create request. The nodes of the workflow must not depend on each other in a cycle
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Param workflow body model.WorkflowCreate true "Workflow Create"
+// @Success 201 {object} model.Workflow
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflows [post]
+func (w *Workflows) CreateWorkflow() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		create := &model.WorkflowCreate{}
+		if err := ctx.BindJSON(create); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		workflow, err := w.service.CreateWorkflow(ctx.Request.Context(), create)
+		if err != nil {
+			workflowErr := model.ToCustomJobError(err)
+
+			ctx.JSON(workflowErr.Code, ErrorResponse{Error: workflowErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusCreated, workflow)
+	}
+}
+
+// GetWorkflow godoc
+// @Summary Get a workflow
+// @Description Get a workflow with the given workflow ID
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Success 200 {object} model.Workflow
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflows/{id} [get]
+func (w *Workflows) GetWorkflow() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		workflow, err := w.service.GetWorkflow(ctx.Request.Context(), id)
+		if err != nil {
+			workflowErr := model.ToCustomJobError(err)
+
+			ctx.JSON(workflowErr.Code, ErrorResponse{Error: workflowErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, workflow)
+	}
+}
+
+// DeleteWorkflow godoc
+// @Summary Delete a workflow
+// @Description Delete a workflow with the given workflow ID together with its runs. The jobs of the workflow are kept
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Success 204
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflows/{id} [delete]
+func (w *Workflows) DeleteWorkflow() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		if err := w.service.DeleteWorkflow(ctx.Request.Context(), id); err != nil {
+			workflowErr := model.ToCustomJobError(err)
+
+			ctx.JSON(workflowErr.Code, ErrorResponse{Error: workflowErr.Error()})
+			return
+		}
+
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// ListWorkflows godoc
+// @Summary List workflows
+// @Description List workflows with the given limit and offset
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.Workflow
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflows [get]
+func (w *Workflows) ListWorkflows() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		limit, offset := LimitAndOffset(ctx)
+
+		workflows, err := w.service.ListWorkflows(ctx.Request.Context(), limit, offset)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, workflows)
+	}
+}
+
+// TriggerWorkflow godoc
+// @Summary Trigger a workflow
+// @Description Start a new run of the workflow with the given workflow ID. The jobs of the nodes that don't depend on other nodes are triggered right away, the other nodes follow as the jobs they depend on finish
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Success 202 {object} model.WorkflowRun
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflows/{id}/trigger [post]
+func (w *Workflows) TriggerWorkflow() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		run, err := w.service.TriggerWorkflow(ctx.Request.Context(), id)
+		if err != nil {
+			workflowErr := model.ToCustomJobError(err)
+
+			ctx.JSON(workflowErr.Code, ErrorResponse{Error: workflowErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusAccepted, run)
+	}
+}
+
+// ListWorkflowRuns godoc
+// @Summary List workflow runs
+// @Description List the runs of the workflow with the given workflow ID, latest first, with the given limit and offset
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {object} []model.WorkflowRun
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflows/{id}/runs [get]
+func (w *Workflows) ListWorkflowRuns() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		limit, offset := LimitAndOffset(ctx)
+
+		runs, err := w.service.ListWorkflowRuns(ctx.Request.Context(), id, limit, offset)
+		if err != nil {
+			workflowErr := model.ToCustomJobError(err)
+
+			ctx.JSON(workflowErr.Code, ErrorResponse{Error: workflowErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, runs)
+	}
+}
+
+// GetWorkflowRun godoc
+// @Summary Get a workflow run
+// @Description Get the run with the given run ID of the workflow with the given workflow ID, with the state of each of its nodes
+// @Tags workflows
+// @Accept json
+// @Produce json
+// @Param id path string true "Workflow ID"
+// @Param runId path string true "Workflow Run ID"
+// @Success 200 {object} model.WorkflowRun
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Router /workflows/{id}/runs/{runId} [get]
+func (w *Workflows) GetWorkflowRun() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+
+		id, err := uuid.Parse(ctx.Param("id"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		runID, err := uuid.Parse(ctx.Param("runId"))
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+
+		run, err := w.service.GetWorkflowRun(ctx.Request.Context(), id, runID)
+		if err != nil {
+			workflowErr := model.ToCustomJobError(err)
+
+			ctx.JSON(workflowErr.Code, ErrorResponse{Error: workflowErr.Error()})
+			return
+		}
+
+		ctx.JSON(http.StatusOK, run)
+	}
+}
@@ -0,0 +1,257 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxAssertedBodySize is how much of a response body the assertions of an HTTP job are checked against.
+// The body assertions of a response with a larger body fail.
+const MaxAssertedBodySize = 1 << 20
+
+// ResponseAssertions are checks the response to an HTTP job must pass, besides having a valid response code,
+// for the execution to succeed. E.g. an endpoint that answers with 200 and {"ok": false} can be treated as failed.
+type ResponseAssertions struct {
+	JSONPath   []JSONPathAssertion `json:"json_path,omitempty"`                        // e.g., [{"path": "$.ok", "equals": true}]
+	BodyRegex  string              `json:"body_regex,omitempty"`                       // e.g., "\"status\":\\s*\"done\""
+	MaxLatency *Duration           `json:"max_latency,omitempty" swaggertype:"string"` // e.g., "2s"
+}
+
+// JSONPathAssertion checks the value at a path of a JSON response body. The path is a JSONPath expression
+// made of names and indexes, e.g. "$.data.items[0].status" or "$['data']['items'][0]".
+type JSONPathAssertion struct {
+	Path   string          `json:"path"`                                  // e.g., "$.ok"
+	Equals json.RawMessage `json:"equals,omitempty" swaggertype:"object"` // the value at the path must equal this, the path must just exist when not set
+}
+
+// AssertionError is returned by HTTP executors when the response fails one of the job's assertions.
+type AssertionError struct {
+	Assertion string // the assertion that failed, e.g. "json_path $.ok"
+	Reason    string // why it failed, e.g. "expected true, got false"
+}
+
+func (e *AssertionError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", ErrAssertionFailed, e.Assertion, e.Reason)
+}
+
+func (e *AssertionError) Unwrap() error {
+	return ErrAssertionFailed
+}
+
+// Validate validates a ResponseAssertions struct. A nil ResponseAssertions has no assertions.
+func (ra *ResponseAssertions) Validate() error {
+	if ra == nil {
+		return nil
+	}
+
+	for _, assertion := range ra.JSONPath {
+		if _, err := parseJSONPath(assertion.Path); err != nil {
+			return ErrInvalidAssertions
+		}
+
+		if assertion.Equals != nil && !json.Valid(assertion.Equals) {
+			return ErrInvalidAssertions
+		}
+	}
+
+	if _, err := regexp.Compile(ra.BodyRegex); err != nil {
+		return ErrInvalidAssertions
+	}
+
+	if ra.MaxLatency != nil && *ra.MaxLatency <= 0 {
+		return ErrInvalidAssertions
+	}
+
+	return nil
+}
+
+// HasBodyAssertions reports whether the assertions check the response body.
+func (ra *ResponseAssertions) HasBodyAssertions() bool {
+	return ra != nil && (len(ra.JSONPath) > 0 || ra.BodyRegex != "")
+}
+
+// Check checks the response, with the given body that took the given latency, against the assertions.
+// It returns an *AssertionError for the first assertion the response fails. A truncated body, one that
+// was cut off at MaxAssertedBodySize, fails the body assertions.
+func (ra *ResponseAssertions) Check(body []byte, truncated bool, latency time.Duration) error {
+	if ra == nil {
+		return nil
+	}
+
+	if ra.MaxLatency != nil && latency > ra.MaxLatency.Duration() {
+		return &AssertionError{
+			Assertion: "max_latency " + ra.MaxLatency.Duration().String(),
+			Reason:    "the response took " + latency.String(),
+		}
+	}
+
+	if ra.HasBodyAssertions() && truncated {
+		return &AssertionError{
+			Assertion: "body",
+			Reason:    fmt.Sprintf("the response body is larger than %d bytes", MaxAssertedBodySize),
+		}
+	}
+
+	if ra.BodyRegex != "" {
+		if !regexp.MustCompile(ra.BodyRegex).Match(body) {
+			return &AssertionError{Assertion: "body_regex " + ra.BodyRegex, Reason: "the response body doesn't match"}
+		}
+	}
+
+	if len(ra.JSONPath) == 0 {
+		return nil
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return &AssertionError{Assertion: "json_path", Reason: "the response body is not valid JSON"}
+	}
+
+	for _, assertion := range ra.JSONPath {
+		if err := assertion.check(document); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a JSONPathAssertion) check(document interface{}) error {
+	path, err := parseJSONPath(a.Path)
+	if err != nil {
+		return &AssertionError{Assertion: "json_path " + a.Path, Reason: err.Error()}
+	}
+
+	value, ok := path.lookup(document)
+	if !ok {
+		return &AssertionError{Assertion: "json_path " + a.Path, Reason: "the path doesn't exist"}
+	}
+
+	if a.Equals == nil {
+		return nil
+	}
+
+	var expected interface{}
+	if err := json.Unmarshal(a.Equals, &expected); err != nil {
+		return &AssertionError{Assertion: "json_path " + a.Path, Reason: err.Error()}
+	}
+
+	if !reflect.DeepEqual(expected, value) {
+		got, _ := json.Marshal(value)
+		return &AssertionError{
+			Assertion: "json_path " + a.Path,
+			Reason:    fmt.Sprintf("expected %s, got %s", compactJSON(a.Equals), got),
+		}
+	}
+
+	return nil
+}
+
+func compactJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return string(data)
+	}
+
+	return buf.String()
+}
+
+// jsonPath is a parsed JSONPath expression: a sequence of object names (string) and array indexes (int).
+type jsonPath []interface{}
+
+// parseJSONPath parses a JSONPath expression made of names and indexes, e.g. "$.items[0]['first name']".
+// Negative indexes count from the end of an array. Wildcards, filters and recursive descent are not supported.
+func parseJSONPath(expression string) (jsonPath, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return nil, fmt.Errorf("path must start with $")
+	}
+
+	var path jsonPath
+
+	rest := expression[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+
+			name := rest[1 : end+1]
+			if name == "" || name == "*" {
+				return nil, fmt.Errorf("invalid name in path at %q", rest)
+			}
+
+			path = append(path, name)
+			rest = rest[end+1:]
+
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed bracket in path at %q", rest)
+			}
+
+			selector := rest[1:end]
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				path = append(path, selector[1:len(selector)-1])
+			} else {
+				index, err := strconv.Atoi(selector)
+				if err != nil {
+					return nil, fmt.Errorf("invalid index in path at %q", rest)
+				}
+
+				path = append(path, index)
+			}
+
+			rest = rest[end+1:]
+
+		default:
+			return nil, fmt.Errorf("unexpected character in path at %q", rest)
+		}
+	}
+
+	return path, nil
+}
+
+// lookup returns the value at the path of the decoded JSON document, and whether it exists.
+func (p jsonPath) lookup(document interface{}) (interface{}, bool) {
+	value := document
+
+	for _, step := range p {
+		switch step := step.(type) {
+		case string:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			if value, ok = object[step]; !ok {
+				return nil, false
+			}
+
+		case int:
+			array, ok := value.([]interface{})
+			if !ok {
+				return nil, false
+			}
+
+			if step < 0 {
+				step += len(array)
+			}
+
+			if step < 0 || step >= len(array) {
+				return nil, false
+			}
+
+			value = array[step]
+		}
+	}
+
+	return value, true
+}
@@ -0,0 +1,138 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponseAssertionsValidate(t *testing.T) {
+	latency := Duration(time.Second)
+	zero := Duration(0)
+
+	tests := []struct {
+		name       string
+		assertions *ResponseAssertions
+		want       error
+	}{
+		{name: "no assertions", assertions: nil},
+		{
+			name: "valid assertions",
+			assertions: &ResponseAssertions{
+				JSONPath:   []JSONPathAssertion{{Path: "$.data.items[0]['status']", Equals: json.RawMessage(`"done"`)}, {Path: "$.ok"}},
+				BodyRegex:  `"ok":\s*true`,
+				MaxLatency: &latency,
+			},
+		},
+		{
+			name:       "path without root",
+			assertions: &ResponseAssertions{JSONPath: []JSONPathAssertion{{Path: "data.ok"}}},
+			want:       ErrInvalidAssertions,
+		},
+		{
+			name:       "wildcard path",
+			assertions: &ResponseAssertions{JSONPath: []JSONPathAssertion{{Path: "$.items[*]"}}},
+			want:       ErrInvalidAssertions,
+		},
+		{
+			name:       "invalid regex",
+			assertions: &ResponseAssertions{BodyRegex: `(`},
+			want:       ErrInvalidAssertions,
+		},
+		{
+			name:       "non-positive max latency",
+			assertions: &ResponseAssertions{MaxLatency: &zero},
+			want:       ErrInvalidAssertions,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.assertions.Validate())
+		})
+	}
+}
+
+func TestResponseAssertionsCheck(t *testing.T) {
+	body := []byte(`{"ok": false, "count": 3, "items": [{"status": "done"}, {"status": "failed"}], "error": null}`)
+	latency := Duration(time.Second)
+
+	tests := []struct {
+		name       string
+		assertions *ResponseAssertions
+		body       []byte
+		truncated  bool
+		latency    time.Duration
+		want       string // the failed assertion's error, empty if the response passes
+	}{
+		{
+			name:       "equal values",
+			assertions: &ResponseAssertions{JSONPath: []JSONPathAssertion{{Path: "$.count", Equals: json.RawMessage(`3.0`)}, {Path: "$.items[-1].status", Equals: json.RawMessage(`"failed"`)}}},
+			body:       body,
+		},
+		{
+			name:       "existing paths",
+			assertions: &ResponseAssertions{JSONPath: []JSONPathAssertion{{Path: "$['items'][0]"}, {Path: "$.error"}}},
+			body:       body,
+		},
+		{
+			name:       "different value",
+			assertions: &ResponseAssertions{JSONPath: []JSONPathAssertion{{Path: "$.ok", Equals: json.RawMessage(`true`)}}},
+			body:       body,
+			want:       "assertion failed: json_path $.ok: expected true, got false",
+		},
+		{
+			name:       "missing path",
+			assertions: &ResponseAssertions{JSONPath: []JSONPathAssertion{{Path: "$.items[2]"}}},
+			body:       body,
+			want:       "assertion failed: json_path $.items[2]: the path doesn't exist",
+		},
+		{
+			name:       "body that is not JSON",
+			assertions: &ResponseAssertions{JSONPath: []JSONPathAssertion{{Path: "$.ok"}}},
+			body:       []byte(`OK`),
+			want:       "assertion failed: json_path: the response body is not valid JSON",
+		},
+		{
+			name:       "truncated body",
+			assertions: &ResponseAssertions{BodyRegex: `ok`},
+			body:       body,
+			truncated:  true,
+			want:       "assertion failed: body: the response body is larger than 1048576 bytes",
+		},
+		{
+			name:       "matching regex",
+			assertions: &ResponseAssertions{BodyRegex: `"status":\s*"done"`},
+			body:       body,
+		},
+		{
+			name:       "regex that doesn't match",
+			assertions: &ResponseAssertions{BodyRegex: `"ok":\s*true`},
+			body:       body,
+			want:       `assertion failed: body_regex "ok":\s*true: the response body doesn't match`,
+		},
+		{
+			name:       "slow response",
+			assertions: &ResponseAssertions{MaxLatency: &latency},
+			latency:    2 * time.Second,
+			want:       "assertion failed: max_latency 1s: the response took 2s",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.assertions.Check(tc.body, tc.truncated, tc.latency)
+			if tc.want == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.EqualError(t, err, tc.want)
+			assert.True(t, errors.Is(err, ErrAssertionFailed))
+			assert.Equal(t, FailureClassAssertion, ClassifyFailure(err))
+		})
+	}
+}
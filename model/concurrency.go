@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"gopkg.in/guregu/null.v4"
+)
+
+// ConcurrencyPolicy controls what happens when a scheduled run of a job becomes due while the job's previous
+// run is still in progress, like the concurrency policy of a Kubernetes CronJob.
+type ConcurrencyPolicy string
+
+const (
+	ConcurrencyPolicyAllow   ConcurrencyPolicy = "Allow"   // the new run starts, the previous run continues alongside it
+	ConcurrencyPolicyForbid  ConcurrencyPolicy = "Forbid"  // the new run is skipped
+	ConcurrencyPolicyReplace ConcurrencyPolicy = "Replace" // the previous run is cancelled and replaced by the new run
+)
+
+// DefaultConcurrencyPolicy is used for jobs that don't define their own concurrency policy. The runs of a job
+// overlap like they did before jobs had a concurrency policy, a job opts in to Forbid or Replace.
+const DefaultConcurrencyPolicy = ConcurrencyPolicyAllow
+
+func (cp ConcurrencyPolicy) Valid() bool {
+	switch cp {
+	case ConcurrencyPolicyAllow, ConcurrencyPolicyForbid, ConcurrencyPolicyReplace:
+		return true
+	default:
+		return false
+	}
+}
+
+// EffectiveConcurrencyPolicy returns the job's concurrency policy, or the default one if the job doesn't define it.
+func (j *Job) EffectiveConcurrencyPolicy() ConcurrencyPolicy {
+	if j.ConcurrencyPolicy == "" {
+		return DefaultConcurrencyPolicy
+	}
+
+	return j.ConcurrencyPolicy
+}
+
+// SkipDueRuns skips the scheduled runs of a recurring job that are due at time at and returns them,
+// so they can be recorded as skipped. The job's next run is moved past them.
+func (j *Job) SkipDueRuns(at time.Time) []time.Time {
+	if !j.CronSchedule.Valid || !j.NextRun.Valid || j.NextRun.Time.After(at) {
+		return nil
+	}
+
+	schedule, err := j.Schedule()
+	if err != nil {
+		return nil
+	}
+
+	skipped := occurrences(schedule, j.NextRun.Time, at, maxMissedRunsToRecord)
+	j.NextRun = null.TimeFrom(schedule.Next(at))
+
+	return skipped
+}
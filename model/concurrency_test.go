@@ -0,0 +1,32 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestJobSkipDueRuns(t *testing.T) {
+	nextRun := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	j := &Job{CronSchedule: null.StringFrom("0 * * * *"), NextRun: null.TimeFrom(nextRun)}
+
+	// nothing is skipped before the next run is due
+	assert.Nil(t, j.SkipDueRuns(nextRun.Add(-time.Minute)))
+	assert.Equal(t, nextRun, j.NextRun.Time)
+
+	skipped := j.SkipDueRuns(nextRun.Add(time.Hour + 30*time.Minute))
+	assert.Equal(t, []time.Time{nextRun, nextRun.Add(time.Hour)}, skipped)
+	assert.Equal(t, nextRun.Add(2*time.Hour), j.NextRun.Time)
+
+	// one-off jobs have no runs to skip
+	oneOff := &Job{ExecuteAt: null.TimeFrom(nextRun), NextRun: null.TimeFrom(nextRun)}
+	assert.Nil(t, oneOff.SkipDueRuns(nextRun.Add(time.Hour)))
+}
+
+func TestJobEffectiveConcurrencyPolicy(t *testing.T) {
+	assert.Equal(t, ConcurrencyPolicyAllow, (&Job{}).EffectiveConcurrencyPolicy())
+	assert.Equal(t, ConcurrencyPolicyReplace, (&Job{ConcurrencyPolicy: ConcurrencyPolicyReplace}).EffectiveConcurrencyPolicy())
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Duration is a time.Duration that is encoded in JSON as a Go duration string, e.g. "1m30s".
+type Duration time.Duration
+
+var errInvalidDuration = errors.New("duration must be a string such as \"30s\" or \"1m30s\"")
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return errInvalidDuration
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return errInvalidDuration
+	}
+
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// Duration returns the value as a time.Duration.
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
@@ -1,12 +1,15 @@
 package model
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrInvalidJobType       = errors.New("job type must be either HTTP or AMQP")
+	ErrInvalidJobType       = errors.New("job type must be either HTTP, AMQP, GRPC, KAFKA, MQTT, NATS or REDIS")
 	ErrInvalidJobID         = errors.New("job ID must be a valid UUID")
 	ErrInvalidJobStatus     = errors.New("job status must be either PENDING, SCHEDULED, SUCCESSFUL, or FAILED")
-	ErrInvalidJobFields     = errors.New("job cannot have both HTTP and AMQP fields defined")
+	ErrInvalidJobFields     = errors.New("job can only have the fields of its type defined")
 	ErrInvalidJobSchedule   = errors.New("job must have only one of execute_at and cron_schedule defined")
 	ErrInvalidCronSchedule  = errors.New("invalid cron schedule")
 	ErrInvalidExecuteAt     = errors.New("execute_at must be in the future")
@@ -16,16 +19,110 @@ var (
 	ErrAMQPJobNotDefined    = errors.New("AMQP job must be defined")
 	ErrEmptyExchange        = errors.New("exchange must be defined for AMQP jobs")
 	ErrEmptyRoutingKey      = errors.New("routing key must be defined for AMQP jobs")
-	ErrInvalidAuthType      = errors.New("auth type must be either none, basic, or bearer")
+	ErrInvalidAuthType      = errors.New("auth type must be either none, basic, bearer, oauth2_client_credentials, or hmac")
 	ErrEmptyUsername        = errors.New("username must be defined for basic auth")
 	ErrEmptyPassword        = errors.New("password must be defined for basic auth")
 	ErrEmptyBearerToken     = errors.New("bearer token must be defined for bearer auth")
+	ErrEmptyTokenURL        = errors.New("token URL must be defined for oauth2_client_credentials auth")
+	ErrEmptyClientID        = errors.New("client ID must be defined for oauth2_client_credentials auth")
+	ErrEmptyClientSecret    = errors.New("client secret must be defined for oauth2_client_credentials auth")
+	ErrEmptySigningSecret   = errors.New("signing secret must be defined for hmac auth")
+	ErrInvalidHMACAlgorithm = errors.New("signature algorithm must be either sha256 or sha512")
+	ErrInvalidHMACHeader    = errors.New("signature header must be a valid header name")
 	ErrAuthMethodNotDefined = errors.New("auth method must be defined")
 	ErrJobNotFound          = errors.New("job not found")
 	ErrInvalidResponseCode  = errors.New("invalid response code")
 	ErrInvalidBodyEncoding  = errors.New("invalid body encoding")
+
+	ErrInvalidRetryMaxAttempts  = errors.New("retry policy max_attempts must not be negative")
+	ErrInvalidRetryInterval     = errors.New("retry policy intervals must not be negative and initial_interval must not exceed max_interval")
+	ErrInvalidRetryMultiplier   = errors.New("retry policy multiplier must be at least 1")
+	ErrInvalidFailureClass      = errors.New("retry policy retry_on must only contain http_4xx, http_5xx, assertion, connection, timeout or other")
+	ErrInvalidTimeout           = errors.New("timeout must be greater than zero")
+	ErrExecutionTimedOut        = errors.New("job execution timed out")
+	ErrJobLockLost              = errors.New("job lock was taken over by another instance")
+	ErrStaleFencingToken        = errors.New("job was claimed again since the execution started")
+	ErrEmptyTags                = errors.New("tags must not be empty")
+	ErrInvalidMisfirePolicy     = errors.New("misfire policy must be either fire_once_now, fire_all_missed or skip_to_next")
+	ErrInvalidMisfireThreshold  = errors.New("misfire threshold must be greater than zero")
+	ErrInvalidTimezone          = errors.New("timezone must be a valid IANA time zone name, e.g. Europe/Ljubljana")
+	ErrInvalidConcurrencyPolicy = errors.New("concurrency policy must be either Allow, Forbid or Replace")
+	ErrExecutionReplaced        = errors.New("job execution was replaced by a new run of the job")
+	ErrInvalidPriority          = errors.New("priority must be between -100 and 100")
+	ErrInvalidPool              = errors.New("pool must be a non-empty name of at most 64 characters")
+	ErrInvalidTemplate          = errors.New("invalid template")
+	ErrInvalidResponseCapture   = errors.New("response capture headers must not be empty and body_limit must be between 0 and 65536")
+	ErrInvalidAssertions        = errors.New("assertions must have valid JSONPath expressions and equals values, a valid body regex and a positive max latency")
+	ErrAssertionFailed          = errors.New("assertion failed")
+	ErrInvalidTLSConfig         = errors.New("invalid TLS configuration")
+	ErrInvalidProxy             = errors.New("proxy url must be an http, https or socks5 URL without credentials, and a proxy password requires a username")
+	ErrInvalidRedirectPolicy    = errors.New("redirect policy mode must be either follow, none or same_host, and max_hops must be between 0 and 20")
+
+	ErrInvalidWorkflowID         = errors.New("invalid workflow ID")
+	ErrEmptyWorkflowName         = errors.New("workflow name must not be empty")
+	ErrEmptyWorkflowNodes        = errors.New("workflow must have at least one node")
+	ErrInvalidWorkflowNode       = errors.New("workflow nodes must have a unique name and a job ID")
+	ErrDuplicateWorkflowJob      = errors.New("a job can only be run by one node of a workflow")
+	ErrUnknownWorkflowDependency = errors.New("workflow nodes can only depend on nodes of the same workflow")
+	ErrInvalidWorkflowCondition  = errors.New("workflow dependency condition must be either success, failure or always")
+	ErrWorkflowCycle             = errors.New("workflow nodes must not depend on each other in a cycle")
+	ErrWorkflowNotFound          = errors.New("workflow not found")
+	ErrWorkflowRunNotFound       = errors.New("workflow run not found")
+
+	ErrGRPCJobNotDefined      = errors.New("gRPC job must be defined")
+	ErrEmptyGRPCTarget        = errors.New("target must be defined for gRPC jobs")
+	ErrInvalidGRPCMethod      = errors.New("method must be a full gRPC method name, e.g. /billing.v1.Invoices/Close")
+	ErrInvalidGRPCMessage     = errors.New("message must be a JSON object")
+	ErrInvalidGRPCTLS         = errors.New("a plaintext gRPC job can't have TLS options")
+	ErrInvalidGRPCDescriptors = errors.New("descriptor set must be a FileDescriptorSet with the method")
+	ErrGRPCStreamingMethod    = errors.New("only unary gRPC methods can be called")
+
+	ErrKafkaJobNotDefined = errors.New("Kafka job must be defined")
+	ErrEmptyKafkaBrokers  = errors.New("brokers must be defined for Kafka jobs")
+	ErrInvalidKafkaBroker = errors.New("brokers must be host:port addresses, e.g. kafka-1.internal:9092")
+	ErrInvalidKafkaTopic  = errors.New("topic must be a valid Kafka topic name")
+	ErrInvalidKafkaAcks   = errors.New("acks must be either none, leader or all")
+	ErrInvalidKafkaSASL   = errors.New("SASL mechanism must be either plain, scram-sha-256 or scram-sha-512, with a username and password")
+
+	ErrMQTTJobNotDefined      = errors.New("MQTT job must be defined")
+	ErrInvalidMQTTBroker      = errors.New("broker URL must be an MQTT broker URL, e.g. mqtts://mqtt.internal:8883")
+	ErrInvalidMQTTTopic       = errors.New("topic must be an MQTT topic name without wildcards")
+	ErrInvalidMQTTQoS         = errors.New("QoS must be either 0, 1 or 2")
+	ErrInvalidMQTTCredentials = errors.New("a password can only be set together with a username")
+	ErrInvalidMQTTTLS         = errors.New("TLS options can only be set for a broker URL with the ssl, tls, mqtts or wss scheme")
+
+	ErrNATSJobNotDefined      = errors.New("NATS job must be defined")
+	ErrInvalidNATSServer      = errors.New("servers must be NATS server URLs, e.g. nats://nats-1.internal:4222")
+	ErrInvalidNATSSubject     = errors.New("subject must be a NATS subject without wildcards")
+	ErrInvalidNATSHeaders     = errors.New("header names must not be empty or contain colons or whitespace")
+	ErrInvalidNATSMsgID       = errors.New("msg_id can only be set for JetStream jobs")
+	ErrInvalidNATSCredentials = errors.New("NATS jobs authenticate with only one of a username and password, a token or an absolute credentials_file path")
+
+	ErrRedisJobNotDefined  = errors.New("Redis job must be defined")
+	ErrInvalidRedisURL     = errors.New("url must be a Redis URL, e.g. rediss://redis.internal:6380/0")
+	ErrInvalidRedisTLS     = errors.New("TLS options can only be set for a URL with the rediss scheme")
+	ErrInvalidRedisCommand = errors.New("command must be either XADD, PUBLISH, DEL or EXPIRE")
+	ErrInvalidRedisStream  = errors.New("XADD needs a stream")
+	ErrEmptyRedisFields    = errors.New("XADD needs at least one field")
+	ErrInvalidRedisMaxLen  = errors.New("max_len must not be negative")
+	ErrInvalidRedisChannel = errors.New("PUBLISH needs a channel")
+	ErrInvalidRedisPattern = errors.New("DEL and EXPIRE need a key pattern")
+	ErrInvalidRedisTTL     = errors.New("EXPIRE needs a positive ttl")
 )
 
+// HTTPStatusError is returned by HTTP executors when the response code is not one of the valid response codes.
+type HTTPStatusError struct {
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("%s: %d", ErrInvalidResponseCode, e.StatusCode)
+}
+
+func (e *HTTPStatusError) Unwrap() error {
+	return ErrInvalidResponseCode
+}
+
 type CustomError struct {
 	Err  error
 	Code int
@@ -36,11 +133,27 @@ func (e *CustomError) Error() string {
 }
 
 func ToCustomJobError(err error) *CustomError {
+	// template and TLS configuration errors are wrapped with the reason they are invalid
+	if errors.Is(err, ErrInvalidTemplate) || errors.Is(err, ErrInvalidTLSConfig) {
+		return &CustomError{err, 400}
+	}
+
 	switch err {
 	case ErrInvalidJobType, ErrInvalidJobID, ErrInvalidJobStatus, ErrInvalidJobFields, ErrInvalidJobSchedule, ErrInvalidCronSchedule, ErrInvalidExecuteAt,
 		ErrEmptyHTTPJobURL, ErrHTTPJobNotDefined, ErrEmptyHTTPJobMethod, ErrAMQPJobNotDefined, ErrEmptyExchange, ErrEmptyRoutingKey,
-		ErrInvalidAuthType, ErrEmptyUsername, ErrEmptyPassword, ErrEmptyBearerToken, ErrAuthMethodNotDefined,
-		ErrJobNotFound:
+		ErrInvalidAuthType, ErrEmptyUsername, ErrEmptyPassword, ErrEmptyBearerToken, ErrEmptyTokenURL, ErrEmptyClientID, ErrEmptyClientSecret, ErrAuthMethodNotDefined,
+		ErrEmptySigningSecret, ErrInvalidHMACAlgorithm, ErrInvalidHMACHeader,
+		ErrInvalidRetryMaxAttempts, ErrInvalidRetryInterval, ErrInvalidRetryMultiplier, ErrInvalidFailureClass, ErrInvalidTimeout,
+		ErrInvalidMisfirePolicy, ErrInvalidMisfireThreshold, ErrInvalidTimezone, ErrInvalidConcurrencyPolicy, ErrInvalidPriority, ErrInvalidPool, ErrInvalidResponseCapture, ErrInvalidAssertions, ErrEmptyTags, ErrJobNotFound,
+		ErrInvalidProxy, ErrInvalidRedirectPolicy,
+		ErrInvalidWorkflowID, ErrEmptyWorkflowName, ErrEmptyWorkflowNodes, ErrInvalidWorkflowNode, ErrDuplicateWorkflowJob,
+		ErrUnknownWorkflowDependency, ErrInvalidWorkflowCondition, ErrWorkflowCycle, ErrWorkflowNotFound, ErrWorkflowRunNotFound,
+		ErrGRPCJobNotDefined, ErrEmptyGRPCTarget, ErrInvalidGRPCMethod, ErrInvalidGRPCMessage, ErrInvalidGRPCTLS, ErrInvalidGRPCDescriptors, ErrGRPCStreamingMethod,
+		ErrKafkaJobNotDefined, ErrEmptyKafkaBrokers, ErrInvalidKafkaBroker, ErrInvalidKafkaTopic, ErrInvalidKafkaAcks, ErrInvalidKafkaSASL,
+		ErrMQTTJobNotDefined, ErrInvalidMQTTBroker, ErrInvalidMQTTTopic, ErrInvalidMQTTQoS, ErrInvalidMQTTCredentials, ErrInvalidMQTTTLS,
+		ErrNATSJobNotDefined, ErrInvalidNATSServer, ErrInvalidNATSSubject, ErrInvalidNATSHeaders, ErrInvalidNATSMsgID, ErrInvalidNATSCredentials,
+		ErrRedisJobNotDefined, ErrInvalidRedisURL, ErrInvalidRedisTLS, ErrInvalidRedisCommand, ErrInvalidRedisStream, ErrEmptyRedisFields,
+		ErrInvalidRedisMaxLen, ErrInvalidRedisChannel, ErrInvalidRedisPattern, ErrInvalidRedisTTL:
 		return &CustomError{err, 400}
 
 	default:
@@ -2,6 +2,7 @@ package model
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 )
 
@@ -16,6 +17,8 @@ func TestToCustomJobError(t *testing.T) {
 		{"ErrEmptyPassword", ErrEmptyPassword, 400},
 		{"ErrEmptyBearerToken", ErrEmptyBearerToken, 400},
 		{"ErrAuthMethodNotDefined", ErrAuthMethodNotDefined, 400},
+		{"ErrInvalidTemplate", fmt.Errorf("%w: body: unexpected EOF", ErrInvalidTemplate), 400},
+		{"ErrInvalidTLSConfig", fmt.Errorf("%w: ca_cert has no valid PEM encoded certificates", ErrInvalidTLSConfig), 400},
 		{"Other error", errors.New("other error"), 500},
 	}
 
@@ -0,0 +1,148 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// grpcMethodPattern matches a full gRPC method name, e.g. "/billing.v1.Invoices/Close".
+var grpcMethodPattern = regexp.MustCompile(`^/?([A-Za-z_][\w]*(?:\.[A-Za-z_][\w]*)*)/([A-Za-z_]\w*)$`)
+
+// GRPCJob calls a unary method of a gRPC service. The request message is given as JSON and encoded with the
+// method's descriptors, which are fetched with server reflection or taken from the job's descriptor set.
+type GRPCJob struct {
+	Target        string            `json:"target"`                                        // e.g., "billing.internal:443"
+	Method        string            `json:"method"`                                        // full method name, e.g., "/billing.v1.Invoices/Close"
+	Message       json.RawMessage   `json:"message,omitempty" swaggertype:"object"`        // the request message in JSON, e.g., {"period": "2024-01"}
+	Metadata      map[string]string `json:"metadata,omitempty"`                            // e.g., {"authorization": "Bearer s3cr3t"}
+	DescriptorSet []byte            `json:"descriptor_set,omitempty" swaggertype:"string"` // base64 encoded FileDescriptorSet with the method (protoc --include_imports -o), server reflection is used when not set
+	Plaintext     bool              `json:"plaintext,omitempty"`                           // connect without TLS
+	TLS           *TLSConfig        `json:"tls,omitempty"`                                 // client certificate, CA bundle, server name and minimum version of the TLS connection
+}
+
+// Validate validates a GRPCJob struct.
+func (grpcJob *GRPCJob) Validate() error {
+	if grpcJob == nil {
+		return ErrGRPCJobNotDefined
+	}
+
+	if grpcJob.Target == "" {
+		return ErrEmptyGRPCTarget
+	}
+
+	if !grpcMethodPattern.MatchString(grpcJob.Method) {
+		return ErrInvalidGRPCMethod
+	}
+
+	if len(grpcJob.Message) > 0 {
+		var message map[string]interface{}
+		if err := json.Unmarshal(grpcJob.Message, &message); err != nil {
+			return ErrInvalidGRPCMessage
+		}
+	}
+
+	if grpcJob.Plaintext && grpcJob.TLS != nil {
+		return ErrInvalidGRPCTLS
+	}
+
+	if err := grpcJob.TLS.Validate(); err != nil {
+		return err
+	}
+
+	// with a descriptor set the message can be checked against the method's request message
+	if grpcJob.DescriptorSet != nil {
+		method, err := grpcJob.MethodDescriptor()
+		if errors.Is(err, ErrGRPCStreamingMethod) {
+			return err
+		}
+		if err != nil {
+			return ErrInvalidGRPCDescriptors
+		}
+
+		if _, err := grpcJob.RequestMessage(method); err != nil {
+			return ErrInvalidGRPCMessage
+		}
+	}
+
+	return nil
+}
+
+// ServiceAndMethod returns the full name of the service and the name of the method, e.g. "billing.v1.Invoices" and "Close".
+func (grpcJob *GRPCJob) ServiceAndMethod() (protoreflect.FullName, protoreflect.Name) {
+	match := grpcMethodPattern.FindStringSubmatch(grpcJob.Method)
+	if match == nil {
+		return "", ""
+	}
+
+	return protoreflect.FullName(match[1]), protoreflect.Name(match[2])
+}
+
+// FullMethod returns the method name as it is sent to the server, e.g. "/billing.v1.Invoices/Close".
+func (grpcJob *GRPCJob) FullMethod() string {
+	return "/" + strings.TrimPrefix(grpcJob.Method, "/")
+}
+
+// MethodDescriptor returns the descriptor of the method from the job's descriptor set.
+func (grpcJob *GRPCJob) MethodDescriptor() (protoreflect.MethodDescriptor, error) {
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(grpcJob.DescriptorSet, &set); err != nil {
+		return nil, err
+	}
+
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, err
+	}
+
+	return grpcJob.FindMethod(files)
+}
+
+// RequestMessage returns the job's message as the request message of the method.
+func (grpcJob *GRPCJob) RequestMessage(method protoreflect.MethodDescriptor) (*dynamicpb.Message, error) {
+	message := dynamicpb.NewMessage(method.Input())
+	if len(grpcJob.Message) == 0 {
+		return message, nil
+	}
+
+	if err := protojson.Unmarshal(grpcJob.Message, message); err != nil {
+		return nil, err
+	}
+
+	return message, nil
+}
+
+// FindMethod returns the descriptor of the method from the files. Only unary methods can be called.
+func (grpcJob *GRPCJob) FindMethod(files *protoregistry.Files) (protoreflect.MethodDescriptor, error) {
+	serviceName, methodName := grpcJob.ServiceAndMethod()
+
+	descriptor, err := files.FindDescriptorByName(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, ErrInvalidGRPCMethod
+	}
+
+	method := service.Methods().ByName(methodName)
+	if method == nil {
+		return nil, ErrInvalidGRPCMethod
+	}
+
+	if method.IsStreamingClient() || method.IsStreamingServer() {
+		return nil, ErrGRPCStreamingMethod
+	}
+
+	return method, nil
+}
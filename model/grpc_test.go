@@ -0,0 +1,88 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+func TestGRPCJobValidate(t *testing.T) {
+	set, err := proto.Marshal(&descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto)},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		job  *GRPCJob
+		want error
+	}{
+		{
+			name: "valid job",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "/billing.v1.Invoices/Close", Message: []byte(`{"period": "2024-01"}`)},
+		},
+		{
+			name: "valid job: method without leading slash",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "billing.v1.Invoices/Close"},
+		},
+		{
+			name: "valid job: descriptor set",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "/grpc.health.v1.Health/Check", Message: []byte(`{"service": "billing"}`), DescriptorSet: set},
+		},
+		{
+			name: "invalid job: not defined",
+			want: ErrGRPCJobNotDefined,
+		},
+		{
+			name: "invalid job: empty target",
+			job:  &GRPCJob{Method: "/billing.v1.Invoices/Close"},
+			want: ErrEmptyGRPCTarget,
+		},
+		{
+			name: "invalid job: method without service",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "Close"},
+			want: ErrInvalidGRPCMethod,
+		},
+		{
+			name: "invalid job: message is not an object",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "/billing.v1.Invoices/Close", Message: []byte(`["2024-01"]`)},
+			want: ErrInvalidGRPCMessage,
+		},
+		{
+			name: "invalid job: plaintext with TLS options",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "/billing.v1.Invoices/Close", Plaintext: true, TLS: &TLSConfig{ServerName: "billing.internal"}},
+			want: ErrInvalidGRPCTLS,
+		},
+		{
+			name: "invalid job: descriptor set without the method",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "/billing.v1.Invoices/Close", DescriptorSet: set},
+			want: ErrInvalidGRPCDescriptors,
+		},
+		{
+			name: "invalid job: descriptor set is not a FileDescriptorSet",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "/grpc.health.v1.Health/Check", DescriptorSet: []byte("not a descriptor set")},
+			want: ErrInvalidGRPCDescriptors,
+		},
+		{
+			name: "invalid job: message doesn't match the request message",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "/grpc.health.v1.Health/Check", Message: []byte(`{"period": "2024-01"}`), DescriptorSet: set},
+			want: ErrInvalidGRPCMessage,
+		},
+		{
+			name: "invalid job: streaming method",
+			job:  &GRPCJob{Target: "billing.internal:443", Method: "/grpc.health.v1.Health/Watch", DescriptorSet: set},
+			want: ErrGRPCStreamingMethod,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.job.Validate())
+		})
+	}
+}
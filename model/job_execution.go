@@ -7,12 +7,20 @@ import (
 )
 
 type JobExecution struct {
-	ID           int         `json:"id"`
-	JobID        uuid.UUID   `json:"job_id"`
-	StartTime    time.Time   `json:"start_time"`
-	EndTime      time.Time   `json:"end_time"`
-	Success      bool        `json:"success"`
-	ErrorMessage null.String `json:"error_message,omitempty" swaggertype:"string"`
+	ID           int                `json:"id"`
+	JobID        uuid.UUID          `json:"job_id"`
+	RunID        uuid.NullUUID      `json:"run_id" swaggertype:"string"`       // links the attempts of one run of the job
+	ExecutionID  uuid.NullUUID      `json:"execution_id" swaggertype:"string"` // the execution's ID, as passed to the job's templates
+	Attempt      int                `json:"attempt"`
+	Trigger      RunTrigger         `json:"trigger"` // what started the run, e.g. "scheduled" or "manual"
+	StartTime    time.Time          `json:"start_time"`
+	EndTime      time.Time          `json:"end_time"`
+	Status       JobExecutionStatus `json:"status"`
+	Success      bool               `json:"success"`
+	ErrorMessage null.String        `json:"error_message,omitempty" swaggertype:"string"`
+
+	// the response to an execution of an HTTP job (null if no response was received)
+	Response *HTTPResponse `json:"response,omitempty"`
 }
 
 type JobExecutionStatus string
@@ -20,4 +28,16 @@ type JobExecutionStatus string
 const (
 	JobExecutionStatusSuccessful JobExecutionStatus = "SUCCESSFUL"
 	JobExecutionStatusFailed     JobExecutionStatus = "FAILED"
+	JobExecutionStatusTimedOut   JobExecutionStatus = "TIMED_OUT"
+	JobExecutionStatusMissed     JobExecutionStatus = "MISSED"  // the scheduled run was skipped by the job's misfire policy
+	JobExecutionStatusSkipped    JobExecutionStatus = "SKIPPED" // the scheduled run was skipped by the job's concurrency policy
+)
+
+// RunTrigger tells what started a run of a job.
+type RunTrigger string
+
+const (
+	RunTriggerScheduled RunTrigger = "scheduled" // the run was due according to the job's schedule
+	RunTriggerManual    RunTrigger = "manual"    // the run was triggered through the API
+	RunTriggerWorkflow  RunTrigger = "workflow"  // the run was triggered by a run of a workflow the job is a node of
 )
@@ -202,6 +202,78 @@ func TestAuthValidate(t *testing.T) {
 			},
 			want: ErrEmptyBearerToken,
 		},
+		{
+			name: "valid auth: oauth2 client credentials",
+			auth: Auth{
+				Type:         AuthTypeOAuth2ClientCredentials,
+				TokenURL:     null.StringFrom("https://auth.example.com/oauth/token"),
+				ClientID:     null.StringFrom("scheduler"),
+				ClientSecret: null.StringFrom("secret"),
+			},
+			want: nil,
+		},
+		{
+			name: "invalid auth: missing token url",
+			auth: Auth{
+				Type:         AuthTypeOAuth2ClientCredentials,
+				ClientID:     null.StringFrom("scheduler"),
+				ClientSecret: null.StringFrom("secret"),
+			},
+			want: ErrEmptyTokenURL,
+		},
+		{
+			name: "invalid auth: missing client secret",
+			auth: Auth{
+				Type:     AuthTypeOAuth2ClientCredentials,
+				TokenURL: null.StringFrom("https://auth.example.com/oauth/token"),
+				ClientID: null.StringFrom("scheduler"),
+			},
+			want: ErrEmptyClientSecret,
+		},
+		{
+			name: "valid auth: hmac",
+			auth: Auth{
+				Type:               AuthTypeHMAC,
+				SigningSecret:      null.StringFrom("secret"),
+				SignatureAlgorithm: null.StringFrom("sha512"),
+				SignatureHeader:    null.StringFrom("X-Signature"),
+			},
+			want: nil,
+		},
+		{
+			name: "invalid auth: missing signing secret",
+			auth: Auth{
+				Type: AuthTypeHMAC,
+			},
+			want: ErrEmptySigningSecret,
+		},
+		{
+			name: "invalid auth: unsupported signature algorithm",
+			auth: Auth{
+				Type:               AuthTypeHMAC,
+				SigningSecret:      null.StringFrom("secret"),
+				SignatureAlgorithm: null.StringFrom("md5"),
+			},
+			want: ErrInvalidHMACAlgorithm,
+		},
+		{
+			name: "invalid auth: invalid signature header",
+			auth: Auth{
+				Type:            AuthTypeHMAC,
+				SigningSecret:   null.StringFrom("secret"),
+				SignatureHeader: null.StringFrom("X Signature"),
+			},
+			want: ErrInvalidHMACHeader,
+		},
+		{
+			name: "invalid auth: signature in the timestamp header",
+			auth: Auth{
+				Type:            AuthTypeHMAC,
+				SigningSecret:   null.StringFrom("secret"),
+				SignatureHeader: null.StringFrom("x-scheduler-timestamp"),
+			},
+			want: ErrInvalidHMACHeader,
+		},
 	}
 
 	for _, tc := range tests {
@@ -282,6 +354,28 @@ func TestJobValidate(t *testing.T) {
 			},
 			want: ErrInvalidJobType,
 		},
+		{
+			name: "invalid job: grpc type with HTTPJob",
+			job: Job{
+				ID:        uuid.New(),
+				Type:      JobTypeGRPC,
+				Status:    JobStatusRunning,
+				ExecuteAt: null.TimeFrom(time.Now().Add(time.Minute)),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				GRPCJob: &GRPCJob{
+					Target: "billing.internal:443",
+					Method: "/billing.v1.Invoices/Close",
+				},
+				CreatedAt: time.Now(),
+			},
+			want: ErrInvalidJobFields,
+		},
 		{
 			name: "invalid job: invalid cron expression",
 			job: Job{
@@ -319,6 +413,140 @@ func TestJobValidate(t *testing.T) {
 			},
 			want: ErrInvalidJobSchedule,
 		},
+		{
+			name: "invalid job: non-positive timeout",
+			job: Job{
+				ID:        uuid.New(),
+				Type:      JobTypeHTTP,
+				Status:    JobStatusRunning,
+				ExecuteAt: null.TimeFrom(time.Now().Add(time.Minute)),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				Timeout:   new(Duration),
+				CreatedAt: time.Now(),
+			},
+			want: ErrInvalidTimeout,
+		},
+		{
+			name: "invalid job: unknown misfire policy",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("*/5 * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				MisfirePolicy: "fire_twice",
+				CreatedAt:     time.Now(),
+			},
+			want: ErrInvalidMisfirePolicy,
+		},
+		{
+			name: "invalid job: non-positive misfire threshold",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("*/5 * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				MisfirePolicy:    MisfirePolicySkipToNext,
+				MisfireThreshold: new(Duration),
+				CreatedAt:        time.Now(),
+			},
+			want: ErrInvalidMisfireThreshold,
+		},
+		{
+			name: "invalid job: unknown timezone",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("0 2 * * *"),
+				Timezone:     null.StringFrom("Europe/Atlantis"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				CreatedAt: time.Now(),
+			},
+			want: ErrInvalidTimezone,
+		},
+		{
+			name: "invalid job: unknown concurrency policy",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("*/5 * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				ConcurrencyPolicy: "Queue",
+				CreatedAt:         time.Now(),
+			},
+			want: ErrInvalidConcurrencyPolicy,
+		},
+		{
+			name: "invalid job: priority out of range",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("*/5 * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				Priority:  MaxPriority + 1,
+				CreatedAt: time.Now(),
+			},
+			want: ErrInvalidPriority,
+		},
+		{
+			name: "invalid job: empty pool",
+			job: Job{
+				ID:           uuid.New(),
+				Type:         JobTypeHTTP,
+				Status:       JobStatusRunning,
+				CronSchedule: null.StringFrom("*/5 * * * *"),
+				HTTPJob: &HTTPJob{
+					URL:    "https://example.com",
+					Method: "GET",
+					Auth: Auth{
+						Type: AuthTypeNone,
+					},
+				},
+				Pool:      null.StringFrom(""),
+				CreatedAt: time.Now(),
+			},
+			want: ErrInvalidPool,
+		},
 	}
 
 	for _, tc := range tests {
@@ -328,3 +556,49 @@ func TestJobValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestJobPauseResume(t *testing.T) {
+	nextRun := time.Now().Add(-time.Hour)
+
+	// A recurring job skips the runs it missed while it was paused
+	j := &Job{
+		Status:       JobStatusRunning,
+		CronSchedule: null.StringFrom("*/5 * * * *"),
+		NextRun:      null.TimeFrom(nextRun),
+		RunID:        uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		Attempt:      2,
+		RetryAt:      null.TimeFrom(nextRun.Add(time.Minute)),
+	}
+
+	j.Pause()
+	assert.Equal(t, JobStatusStopped, j.Status)
+	assert.False(t, j.RunID.Valid)
+	assert.Equal(t, 0, j.Attempt)
+	assert.False(t, j.RetryAt.Valid)
+
+	j.Resume()
+	assert.Equal(t, JobStatusRunning, j.Status)
+	assert.True(t, j.NextRun.Time.After(time.Now()))
+	assert.True(t, j.NextRun.Time.Before(time.Now().Add(5*time.Minute)))
+
+	// A one-off job keeps its next run
+	j = &Job{
+		Status:    JobStatusRunning,
+		ExecuteAt: null.TimeFrom(nextRun),
+		NextRun:   null.TimeFrom(nextRun),
+	}
+
+	j.Pause()
+	j.Resume()
+	assert.Equal(t, JobStatusRunning, j.Status)
+	assert.Equal(t, null.TimeFrom(nextRun), j.NextRun)
+}
+
+func TestJobCreateToJob(t *testing.T) {
+	// an empty time zone is not set, like on update, rather than UTC (which time.LoadLocation makes of it)
+	j := (&JobCreate{Timezone: null.StringFrom("")}).ToJob()
+	assert.False(t, j.Timezone.Valid)
+
+	j = (&JobCreate{Timezone: null.StringFrom("Europe/Ljubljana")}).ToJob()
+	assert.Equal(t, null.StringFrom("Europe/Ljubljana"), j.Timezone)
+}
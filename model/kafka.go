@@ -0,0 +1,104 @@
+package model
+
+import (
+	"net"
+	"regexp"
+)
+
+// kafkaTopicPattern matches a valid Kafka topic name.
+var kafkaTopicPattern = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+
+// KafkaAcks is how many replicas must acknowledge a record before the execution of a Kafka job succeeds.
+type KafkaAcks string
+
+const (
+	KafkaAcksNone   KafkaAcks = "none"   // the record isn't acknowledged, the execution succeeds once it is sent
+	KafkaAcksLeader KafkaAcks = "leader" // the partition leader has written the record
+	KafkaAcksAll    KafkaAcks = "all"    // all in-sync replicas have written the record (default)
+)
+
+// KafkaSASLMechanism is the SASL mechanism a Kafka job authenticates with.
+type KafkaSASLMechanism string
+
+const (
+	KafkaSASLPlain       KafkaSASLMechanism = "plain"
+	KafkaSASLScramSHA256 KafkaSASLMechanism = "scram-sha-256"
+	KafkaSASLScramSHA512 KafkaSASLMechanism = "scram-sha-512"
+)
+
+// KafkaJob produces a record to a Kafka topic. Records with a key are assigned to the partition the Java
+// client would assign them to, so they are ordered with the records other producers send with the same key.
+type KafkaJob struct {
+	Brokers      []string          `json:"brokers"`                 // e.g., ["kafka-1.internal:9092", "kafka-2.internal:9092"]
+	Topic        string            `json:"topic"`                   // e.g., "billing.invoices"
+	Key          string            `json:"key,omitempty"`           // e.g., "customer-42"
+	Headers      map[string]string `json:"headers,omitempty"`       // e.g., {"source": "scheduler"}
+	Body         string            `json:"body"`                    // e.g., "Hello, world!"
+	BodyEncoding *BodyEncoding     `json:"body_encoding,omitempty"` // e.g., null, "base64"
+	Acks         KafkaAcks         `json:"acks,omitempty"`          // "none", "leader" or "all" (default)
+	SASL         *KafkaSASL        `json:"sasl,omitempty"`          // e.g., {"mechanism": "scram-sha-512", "username": "scheduler", "password": "s3cr3t"}
+	TLS          *TLSConfig        `json:"tls,omitempty"`           // connect with TLS, {} verifies the brokers with the system's CAs
+}
+
+// KafkaSASL is the SASL authentication of a Kafka job.
+type KafkaSASL struct {
+	Mechanism KafkaSASLMechanism `json:"mechanism"` // "plain", "scram-sha-256" or "scram-sha-512"
+	Username  string             `json:"username"`
+	Password  string             `json:"password"`
+}
+
+// Validate validates a KafkaJob struct.
+func (kafkaJob *KafkaJob) Validate() error {
+	if kafkaJob == nil {
+		return ErrKafkaJobNotDefined
+	}
+
+	if len(kafkaJob.Brokers) == 0 {
+		return ErrEmptyKafkaBrokers
+	}
+
+	for _, broker := range kafkaJob.Brokers {
+		if host, port, err := net.SplitHostPort(broker); err != nil || host == "" || port == "" {
+			return ErrInvalidKafkaBroker
+		}
+	}
+
+	if !kafkaTopicPattern.MatchString(kafkaJob.Topic) {
+		return ErrInvalidKafkaTopic
+	}
+
+	if !kafkaJob.BodyEncoding.Valid() {
+		return ErrInvalidBodyEncoding
+	}
+
+	switch kafkaJob.Acks {
+	case "", KafkaAcksNone, KafkaAcksLeader, KafkaAcksAll:
+	default:
+		return ErrInvalidKafkaAcks
+	}
+
+	if err := kafkaJob.SASL.Validate(); err != nil {
+		return err
+	}
+
+	return kafkaJob.TLS.Validate()
+}
+
+// Validate validates a KafkaSASL struct. A nil KafkaSASL doesn't authenticate.
+func (sasl *KafkaSASL) Validate() error {
+	if sasl == nil {
+		return nil
+	}
+
+	switch sasl.Mechanism {
+	case KafkaSASLPlain, KafkaSASLScramSHA256, KafkaSASLScramSHA512:
+	default:
+		return ErrInvalidKafkaSASL
+	}
+
+	if sasl.Username == "" || sasl.Password == "" {
+		return ErrInvalidKafkaSASL
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKafkaJobValidate(t *testing.T) {
+	invalidEncoding := BodyEncoding("hex")
+
+	tests := []struct {
+		name string
+		job  *KafkaJob
+		want error
+	}{
+		{
+			name: "valid job",
+			job:  &KafkaJob{Brokers: []string{"kafka-1.internal:9092", "kafka-2.internal:9092"}, Topic: "billing.invoices", Body: "Hello, world!"},
+		},
+		{
+			name: "valid job: acks, SASL and TLS",
+			job: &KafkaJob{
+				Brokers: []string{"kafka-1.internal:9093"},
+				Topic:   "billing.invoices",
+				Acks:    KafkaAcksLeader,
+				SASL:    &KafkaSASL{Mechanism: KafkaSASLScramSHA512, Username: "scheduler", Password: "s3cr3t"},
+				TLS:     &TLSConfig{},
+			},
+		},
+		{
+			name: "invalid job: not defined",
+			want: ErrKafkaJobNotDefined,
+		},
+		{
+			name: "invalid job: no brokers",
+			job:  &KafkaJob{Topic: "billing.invoices"},
+			want: ErrEmptyKafkaBrokers,
+		},
+		{
+			name: "invalid job: broker without port",
+			job:  &KafkaJob{Brokers: []string{"kafka-1.internal"}, Topic: "billing.invoices"},
+			want: ErrInvalidKafkaBroker,
+		},
+		{
+			name: "invalid job: empty topic",
+			job:  &KafkaJob{Brokers: []string{"kafka-1.internal:9092"}},
+			want: ErrInvalidKafkaTopic,
+		},
+		{
+			name: "invalid job: topic with invalid characters",
+			job:  &KafkaJob{Brokers: []string{"kafka-1.internal:9092"}, Topic: "billing/invoices"},
+			want: ErrInvalidKafkaTopic,
+		},
+		{
+			name: "invalid job: unknown body encoding",
+			job:  &KafkaJob{Brokers: []string{"kafka-1.internal:9092"}, Topic: "billing.invoices", BodyEncoding: &invalidEncoding},
+			want: ErrInvalidBodyEncoding,
+		},
+		{
+			name: "invalid job: unknown acks",
+			job:  &KafkaJob{Brokers: []string{"kafka-1.internal:9092"}, Topic: "billing.invoices", Acks: "1"},
+			want: ErrInvalidKafkaAcks,
+		},
+		{
+			name: "invalid job: unknown SASL mechanism",
+			job:  &KafkaJob{Brokers: []string{"kafka-1.internal:9092"}, Topic: "billing.invoices", SASL: &KafkaSASL{Mechanism: "gssapi", Username: "scheduler", Password: "s3cr3t"}},
+			want: ErrInvalidKafkaSASL,
+		},
+		{
+			name: "invalid job: SASL without password",
+			job:  &KafkaJob{Brokers: []string{"kafka-1.internal:9092"}, Topic: "billing.invoices", SASL: &KafkaSASL{Mechanism: KafkaSASLPlain, Username: "scheduler"}},
+			want: ErrInvalidKafkaSASL,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.job.Validate())
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package model
+
+import (
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/guregu/null.v4"
+)
+
+// MisfirePolicy controls what happens to the scheduled runs of a recurring job that were missed,
+// e.g. because no runner was up when they were due.
+type MisfirePolicy string
+
+const (
+	MisfirePolicyFireOnceNow   MisfirePolicy = "fire_once_now"   // run once now, the other missed runs are skipped
+	MisfirePolicyFireAllMissed MisfirePolicy = "fire_all_missed" // make up for the missed runs one after the other (at most MaxMissedRunsToFire)
+	MisfirePolicySkipToNext    MisfirePolicy = "skip_to_next"    // skip all missed runs and wait for the next scheduled one
+)
+
+const (
+	// DefaultMisfirePolicy is used for jobs that don't define their own misfire policy.
+	DefaultMisfirePolicy = MisfirePolicyFireOnceNow
+
+	// DefaultMisfireThreshold is used for jobs that don't define their own misfire threshold.
+	DefaultMisfireThreshold = time.Minute
+
+	// MaxMissedRunsToFire bounds how many missed runs the fire_all_missed policy makes up for.
+	// Older missed runs are skipped.
+	MaxMissedRunsToFire = 10
+
+	// maxMissedRunsToRecord bounds how many skipped runs are recorded at once.
+	maxMissedRunsToRecord = 100
+)
+
+func (mp MisfirePolicy) Valid() bool {
+	switch mp {
+	case MisfirePolicyFireOnceNow, MisfirePolicyFireAllMissed, MisfirePolicySkipToNext:
+		return true
+	default:
+		return false
+	}
+}
+
+// EffectiveMisfirePolicy returns the job's misfire policy, or the default one if the job doesn't define it.
+func (j *Job) EffectiveMisfirePolicy() MisfirePolicy {
+	if j.MisfirePolicy == "" {
+		return DefaultMisfirePolicy
+	}
+
+	return j.MisfirePolicy
+}
+
+// EffectiveMisfireThreshold returns the job's misfire threshold, or the default one if the job doesn't define it.
+func (j *Job) EffectiveMisfireThreshold() time.Duration {
+	if j.MisfireThreshold == nil {
+		return DefaultMisfireThreshold
+	}
+
+	return j.MisfireThreshold.Duration()
+}
+
+// ApplyMisfirePolicy checks whether the scheduled run of a recurring job that is due at its next run time
+// misfired, i.e. it is picked up at time at, more than the misfire threshold late. If so, the job's misfire
+// policy is applied: the job's next run is moved past the skipped runs, which are returned so they can be
+// recorded as missed. run reports whether the job should be run now.
+func (j *Job) ApplyMisfirePolicy(at time.Time) (missed []time.Time, run bool) {
+	if !j.CronSchedule.Valid || !j.NextRun.Valid || at.Sub(j.NextRun.Time) <= j.EffectiveMisfireThreshold() {
+		return nil, true
+	}
+
+	schedule, err := j.Schedule()
+	if err != nil {
+		return nil, true
+	}
+
+	switch j.EffectiveMisfirePolicy() {
+	case MisfirePolicySkipToNext:
+		missed = occurrences(schedule, j.NextRun.Time, at, maxMissedRunsToRecord)
+		j.NextRun = null.TimeFrom(schedule.Next(at))
+
+		return missed, false
+
+	case MisfirePolicyFireAllMissed:
+		due := occurrences(schedule, j.NextRun.Time, at, maxMissedRunsToRecord+MaxMissedRunsToFire)
+		if len(due) <= MaxMissedRunsToFire {
+			return nil, true
+		}
+
+		// skip the oldest runs, the next ones are run one after the other
+		missed = due[:len(due)-MaxMissedRunsToFire]
+		j.NextRun = null.TimeFrom(due[len(due)-MaxMissedRunsToFire])
+
+		return missed, true
+
+	default:
+		// the run made now stands in for the latest missed run
+		due := occurrences(schedule, j.NextRun.Time, at, maxMissedRunsToRecord+1)
+		if len(due) == 1 {
+			return nil, true
+		}
+
+		return due[:len(due)-1], true
+	}
+}
+
+// occurrences returns the times of the schedule from from (which is one of them) up to to, at most limit of them.
+func occurrences(schedule cron.Schedule, from, to time.Time, limit int) []time.Time {
+	times := []time.Time{from}
+	for t := schedule.Next(from); !t.After(to) && len(times) < limit; t = schedule.Next(t) {
+		times = append(times, t)
+	}
+
+	return times
+}
@@ -0,0 +1,122 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestJobApplyMisfirePolicy(t *testing.T) {
+	nextRun := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	threshold := Duration(5 * time.Minute)
+
+	newJob := func(policy MisfirePolicy) *Job {
+		return &Job{
+			CronSchedule:     null.StringFrom("0 * * * *"),
+			NextRun:          null.TimeFrom(nextRun),
+			MisfirePolicy:    policy,
+			MisfireThreshold: &threshold,
+		}
+	}
+
+	hours := func(from, to int) []time.Time {
+		var times []time.Time
+		for h := from; h <= to; h++ {
+			times = append(times, nextRun.Add(time.Duration(h)*time.Hour))
+		}
+		return times
+	}
+
+	tests := []struct {
+		name        string
+		job         *Job
+		at          time.Time
+		wantMissed  []time.Time
+		wantRun     bool
+		wantNextRun time.Time
+	}{
+		{
+			name:        "late within the threshold",
+			job:         newJob(MisfirePolicySkipToNext),
+			at:          nextRun.Add(4 * time.Minute),
+			wantRun:     true,
+			wantNextRun: nextRun,
+		},
+		{
+			name:        "one-off job",
+			job:         &Job{ExecuteAt: null.TimeFrom(nextRun), NextRun: null.TimeFrom(nextRun), MisfirePolicy: MisfirePolicySkipToNext},
+			at:          nextRun.Add(24 * time.Hour),
+			wantRun:     true,
+			wantNextRun: nextRun,
+		},
+		{
+			name:        "fire once now",
+			job:         newJob(MisfirePolicyFireOnceNow),
+			at:          nextRun.Add(3*time.Hour + 30*time.Minute),
+			wantMissed:  hours(0, 2),
+			wantRun:     true,
+			wantNextRun: nextRun,
+		},
+		{
+			name:        "fire once now is the default",
+			job:         newJob(""),
+			at:          nextRun.Add(10 * time.Minute),
+			wantRun:     true,
+			wantNextRun: nextRun,
+		},
+		{
+			name:        "fire all missed",
+			job:         newJob(MisfirePolicyFireAllMissed),
+			at:          nextRun.Add(3*time.Hour + 30*time.Minute),
+			wantRun:     true,
+			wantNextRun: nextRun,
+		},
+		{
+			name:        "fire all missed skips the oldest runs beyond the bound",
+			job:         newJob(MisfirePolicyFireAllMissed),
+			at:          nextRun.Add(12*time.Hour + 30*time.Minute),
+			wantMissed:  hours(0, 2),
+			wantRun:     true,
+			wantNextRun: nextRun.Add(3 * time.Hour),
+		},
+		{
+			name:        "skip to next",
+			job:         newJob(MisfirePolicySkipToNext),
+			at:          nextRun.Add(2*time.Hour + 30*time.Minute),
+			wantMissed:  hours(0, 2),
+			wantRun:     false,
+			wantNextRun: nextRun.Add(3 * time.Hour),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			missed, run := tc.job.ApplyMisfirePolicy(tc.at)
+
+			assert.Equal(t, tc.wantMissed, missed)
+			assert.Equal(t, tc.wantRun, run)
+			assert.Equal(t, tc.wantNextRun, tc.job.NextRun.Time)
+		})
+	}
+}
+
+func TestJobSetNextRunTimeFireAllMissed(t *testing.T) {
+	nextRun := time.Now().Add(-3 * time.Hour).Truncate(time.Hour)
+
+	// the missed runs are made up for one after the other
+	j := &Job{
+		CronSchedule:  null.StringFrom("0 * * * *"),
+		NextRun:       null.TimeFrom(nextRun),
+		MisfirePolicy: MisfirePolicyFireAllMissed,
+	}
+
+	j.SetNextRunTime(time.Now())
+	assert.Equal(t, nextRun.Add(time.Hour), j.NextRun.Time)
+
+	// other policies continue from now
+	j.MisfirePolicy = MisfirePolicyFireOnceNow
+	j.SetNextRunTime(time.Now())
+	assert.True(t, j.NextRun.Time.After(time.Now()))
+}
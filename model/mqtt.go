@@ -0,0 +1,68 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+)
+
+// mqttTLSSchemes are the schemes of the broker URLs that are connected to with TLS.
+var mqttTLSSchemes = map[string]bool{"ssl": true, "tls": true, "mqtts": true, "wss": true}
+
+// mqttMaxTopicLength is the length of the longest topic name an MQTT packet can hold.
+const mqttMaxTopicLength = 65535
+
+// MQTTJob publishes a message to an MQTT topic. The runner keeps the connection to the broker open and
+// reuses it for the executions of all jobs with the same broker, client ID, credentials and TLS options.
+type MQTTJob struct {
+	BrokerURL       string        `json:"broker_url"`                 // e.g., "mqtts://mqtt.internal:8883", the scheme is tcp, mqtt, ssl, tls, mqtts, ws or wss
+	ClientID        string        `json:"client_id,omitempty"`        // e.g., "scheduler", a random client ID is used when not set
+	Topic           string        `json:"topic"`                      // e.g., "chargers/CP-1042/config"
+	QoS             byte          `json:"qos,omitempty"`              // 0 (default), 1 or 2
+	Retain          bool          `json:"retain,omitempty"`           // the broker keeps the message for the topic's future subscribers
+	Payload         string        `json:"payload"`                    // e.g., "{\"heartbeat_interval\": 300}"
+	PayloadEncoding *BodyEncoding `json:"payload_encoding,omitempty"` // e.g., null, "base64"
+	Username        string        `json:"username,omitempty"`         // e.g., "scheduler"
+	Password        string        `json:"password,omitempty"`         // e.g., "s3cr3t"
+	TLS             *TLSConfig    `json:"tls,omitempty"`              // client certificate, CA bundle, server name and minimum version of a TLS broker URL
+}
+
+// Validate validates an MQTTJob struct.
+func (mqttJob *MQTTJob) Validate() error {
+	if mqttJob == nil {
+		return ErrMQTTJobNotDefined
+	}
+
+	brokerURL, err := url.Parse(mqttJob.BrokerURL)
+	if err != nil || brokerURL.Host == "" {
+		return ErrInvalidMQTTBroker
+	}
+
+	switch brokerURL.Scheme {
+	case "tcp", "mqtt", "ws", "ssl", "tls", "mqtts", "wss":
+	default:
+		return ErrInvalidMQTTBroker
+	}
+
+	// wildcards can only be subscribed to, a message is published to a single topic
+	if mqttJob.Topic == "" || len(mqttJob.Topic) > mqttMaxTopicLength || strings.ContainsAny(mqttJob.Topic, "+#\x00") {
+		return ErrInvalidMQTTTopic
+	}
+
+	if mqttJob.QoS > 2 {
+		return ErrInvalidMQTTQoS
+	}
+
+	if !mqttJob.PayloadEncoding.Valid() {
+		return ErrInvalidBodyEncoding
+	}
+
+	if mqttJob.Password != "" && mqttJob.Username == "" {
+		return ErrInvalidMQTTCredentials
+	}
+
+	if mqttJob.TLS != nil && !mqttTLSSchemes[brokerURL.Scheme] {
+		return ErrInvalidMQTTTLS
+	}
+
+	return mqttJob.TLS.Validate()
+}
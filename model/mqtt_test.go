@@ -0,0 +1,91 @@
+package model
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMQTTJobValidate(t *testing.T) {
+	invalidEncoding := BodyEncoding("hex")
+
+	tests := []struct {
+		name string
+		job  *MQTTJob
+		want error
+	}{
+		{
+			name: "valid job",
+			job:  &MQTTJob{BrokerURL: "tcp://mqtt.internal:1883", Topic: "chargers/CP-1042/config", Payload: `{"heartbeat_interval": 300}`},
+		},
+		{
+			name: "valid job: QoS, credentials and TLS",
+			job: &MQTTJob{
+				BrokerURL: "mqtts://mqtt.internal:8883",
+				ClientID:  "scheduler",
+				Topic:     "chargers/firmware-window",
+				QoS:       2,
+				Retain:    true,
+				Username:  "scheduler",
+				Password:  "s3cr3t",
+				TLS:       &TLSConfig{ServerName: "mqtt.internal"},
+			},
+		},
+		{
+			name: "invalid job: not defined",
+			want: ErrMQTTJobNotDefined,
+		},
+		{
+			name: "invalid job: broker URL without host",
+			job:  &MQTTJob{BrokerURL: "tcp://", Topic: "chargers/announcements"},
+			want: ErrInvalidMQTTBroker,
+		},
+		{
+			name: "invalid job: unknown broker URL scheme",
+			job:  &MQTTJob{BrokerURL: "http://mqtt.internal:1883", Topic: "chargers/announcements"},
+			want: ErrInvalidMQTTBroker,
+		},
+		{
+			name: "invalid job: empty topic",
+			job:  &MQTTJob{BrokerURL: "tcp://mqtt.internal:1883"},
+			want: ErrInvalidMQTTTopic,
+		},
+		{
+			name: "invalid job: topic with wildcard",
+			job:  &MQTTJob{BrokerURL: "tcp://mqtt.internal:1883", Topic: "chargers/+/config"},
+			want: ErrInvalidMQTTTopic,
+		},
+		{
+			name: "invalid job: topic too long",
+			job:  &MQTTJob{BrokerURL: "tcp://mqtt.internal:1883", Topic: strings.Repeat("a", 65536)},
+			want: ErrInvalidMQTTTopic,
+		},
+		{
+			name: "invalid job: QoS above 2",
+			job:  &MQTTJob{BrokerURL: "tcp://mqtt.internal:1883", Topic: "chargers/announcements", QoS: 3},
+			want: ErrInvalidMQTTQoS,
+		},
+		{
+			name: "invalid job: unknown payload encoding",
+			job:  &MQTTJob{BrokerURL: "tcp://mqtt.internal:1883", Topic: "chargers/announcements", PayloadEncoding: &invalidEncoding},
+			want: ErrInvalidBodyEncoding,
+		},
+		{
+			name: "invalid job: password without username",
+			job:  &MQTTJob{BrokerURL: "tcp://mqtt.internal:1883", Topic: "chargers/announcements", Password: "s3cr3t"},
+			want: ErrInvalidMQTTCredentials,
+		},
+		{
+			name: "invalid job: TLS options for a broker URL without TLS",
+			job:  &MQTTJob{BrokerURL: "tcp://mqtt.internal:1883", Topic: "chargers/announcements", TLS: &TLSConfig{ServerName: "mqtt.internal"}},
+			want: ErrInvalidMQTTTLS,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.job.Validate())
+		})
+	}
+}
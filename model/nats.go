@@ -0,0 +1,121 @@
+package model
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// NATSJob publishes a message to a NATS subject. A JetStream job publishes it to the stream that captures
+// the subject and waits for the stream's ack, so the execution only succeeds once the message is stored.
+type NATSJob struct {
+	Servers         []string          `json:"servers"`                    // e.g., ["nats://nats-1.internal:4222", "nats://nats-2.internal:4222"]
+	Subject         string            `json:"subject"`                    // e.g., "billing.invoices.close"
+	Headers         map[string]string `json:"headers,omitempty"`          // e.g., {"Source": "scheduler"}
+	Body            string            `json:"body"`                       // e.g., "Hello, world!"
+	BodyEncoding    *BodyEncoding     `json:"body_encoding,omitempty"`    // e.g., null, "base64"
+	JetStream       bool              `json:"jetstream,omitempty"`        // publish to a JetStream stream and wait for its ack
+	MsgID           string            `json:"msg_id,omitempty"`           // JetStream only, the same ID is sent with every run of the job, so the stream deduplicates the runs within its window, e.g., "nightly-report"
+	Username        string            `json:"username,omitempty"`         // e.g., "scheduler"
+	Password        string            `json:"password,omitempty"`         // e.g., "s3cr3t"
+	Token           string            `json:"token,omitempty"`            // e.g., "s3cr3t"
+	CredentialsFile string            `json:"credentials_file,omitempty"` // e.g., "/etc/scheduler/nats/scheduler.creds", the user's JWT and NKey seed
+	TLS             *TLSConfig        `json:"tls,omitempty"`              // connect with TLS, {} verifies the servers with the system's CAs
+}
+
+// Validate validates a NATSJob struct.
+func (natsJob *NATSJob) Validate() error {
+	if natsJob == nil {
+		return ErrNATSJobNotDefined
+	}
+
+	if len(natsJob.Servers) == 0 {
+		return ErrInvalidNATSServer
+	}
+
+	for _, server := range natsJob.Servers {
+		serverURL, err := url.Parse(server)
+		if err != nil || serverURL.Host == "" {
+			return ErrInvalidNATSServer
+		}
+
+		switch serverURL.Scheme {
+		case "nats", "tls", "ws", "wss":
+		default:
+			return ErrInvalidNATSServer
+		}
+	}
+
+	if !validNATSSubject(natsJob.Subject) {
+		return ErrInvalidNATSSubject
+	}
+
+	for key := range natsJob.Headers {
+		if key == "" || strings.IndexFunc(key, func(r rune) bool { return r == ':' || unicode.IsSpace(r) }) >= 0 {
+			return ErrInvalidNATSHeaders
+		}
+	}
+
+	if !natsJob.BodyEncoding.Valid() {
+		return ErrInvalidBodyEncoding
+	}
+
+	if natsJob.MsgID != "" && !natsJob.JetStream {
+		return ErrInvalidNATSMsgID
+	}
+
+	if err := natsJob.validateCredentials(); err != nil {
+		return err
+	}
+
+	if err := natsJob.TLS.Validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validateCredentials checks that the job authenticates with at most one of a username and password, a token
+// and a credentials file.
+func (natsJob *NATSJob) validateCredentials() error {
+	if natsJob.Password != "" && natsJob.Username == "" {
+		return ErrInvalidNATSCredentials
+	}
+
+	methods := 0
+	for _, isSet := range []bool{natsJob.Username != "", natsJob.Token != "", natsJob.CredentialsFile != ""} {
+		if isSet {
+			methods++
+		}
+	}
+	if methods > 1 {
+		return ErrInvalidNATSCredentials
+	}
+
+	if natsJob.CredentialsFile != "" && !filepath.IsAbs(natsJob.CredentialsFile) {
+		return ErrInvalidNATSCredentials
+	}
+
+	return nil
+}
+
+// validNATSSubject reports whether the subject can be published to: tokens separated by dots, without
+// whitespace and without the wildcards, which can only be subscribed to.
+func validNATSSubject(subject string) bool {
+	if subject == "" {
+		return false
+	}
+
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" || token == "*" || token == ">" {
+			return false
+		}
+
+		if strings.IndexFunc(token, unicode.IsSpace) >= 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,120 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNATSJobValidate(t *testing.T) {
+	invalidEncoding := BodyEncoding("hex")
+	servers := []string{"nats://nats.internal:4222"}
+
+	tests := []struct {
+		name string
+		job  *NATSJob
+		want error
+	}{
+		{
+			name: "valid job",
+			job:  &NATSJob{Servers: servers, Subject: "billing.invoices.close", Body: `{"period": "monthly"}`},
+		},
+		{
+			name: "valid job: JetStream with msg ID, headers and TLS",
+			job: &NATSJob{
+				Servers:   []string{"tls://nats-1.internal:4222", "tls://nats-2.internal:4222"},
+				Subject:   "billing.invoices.close",
+				Headers:   map[string]string{"Source": "scheduler"},
+				JetStream: true,
+				MsgID:     "invoices-2023-10",
+				Username:  "scheduler",
+				Password:  "s3cr3t",
+				TLS:       &TLSConfig{ServerName: "nats.internal"},
+			},
+		},
+		{
+			name: "valid job: credentials file",
+			job:  &NATSJob{Servers: servers, Subject: "billing.invoices.close", CredentialsFile: "/etc/scheduler/nats/scheduler.creds"},
+		},
+		{
+			name: "invalid job: not defined",
+			want: ErrNATSJobNotDefined,
+		},
+		{
+			name: "invalid job: no servers",
+			job:  &NATSJob{Subject: "billing.invoices.close"},
+			want: ErrInvalidNATSServer,
+		},
+		{
+			name: "invalid job: unknown server URL scheme",
+			job:  &NATSJob{Servers: []string{"http://nats.internal:4222"}, Subject: "billing.invoices.close"},
+			want: ErrInvalidNATSServer,
+		},
+		{
+			name: "invalid job: server URL without host",
+			job:  &NATSJob{Servers: []string{"nats://"}, Subject: "billing.invoices.close"},
+			want: ErrInvalidNATSServer,
+		},
+		{
+			name: "invalid job: empty subject",
+			job:  &NATSJob{Servers: servers},
+			want: ErrInvalidNATSSubject,
+		},
+		{
+			name: "invalid job: subject with template action",
+			job:  &NATSJob{Servers: servers, Subject: "billing.{{ .JobID }}"},
+			want: ErrInvalidNATSSubject,
+		},
+		{
+			name: "invalid job: subject with wildcard",
+			job:  &NATSJob{Servers: servers, Subject: "billing.*.close"},
+			want: ErrInvalidNATSSubject,
+		},
+		{
+			name: "invalid job: subject with empty token",
+			job:  &NATSJob{Servers: servers, Subject: "billing..close"},
+			want: ErrInvalidNATSSubject,
+		},
+		{
+			name: "invalid job: subject with whitespace",
+			job:  &NATSJob{Servers: servers, Subject: "billing.invoices close"},
+			want: ErrInvalidNATSSubject,
+		},
+		{
+			name: "invalid job: header key with colon",
+			job:  &NATSJob{Servers: servers, Subject: "billing.invoices.close", Headers: map[string]string{"Source:": "scheduler"}},
+			want: ErrInvalidNATSHeaders,
+		},
+		{
+			name: "invalid job: unknown body encoding",
+			job:  &NATSJob{Servers: servers, Subject: "billing.invoices.close", BodyEncoding: &invalidEncoding},
+			want: ErrInvalidBodyEncoding,
+		},
+		{
+			name: "invalid job: msg ID without JetStream",
+			job:  &NATSJob{Servers: servers, Subject: "billing.invoices.close", MsgID: "invoices-2023-10"},
+			want: ErrInvalidNATSMsgID,
+		},
+		{
+			name: "invalid job: password without username",
+			job:  &NATSJob{Servers: servers, Subject: "billing.invoices.close", Password: "s3cr3t"},
+			want: ErrInvalidNATSCredentials,
+		},
+		{
+			name: "invalid job: username and token",
+			job:  &NATSJob{Servers: servers, Subject: "billing.invoices.close", Username: "scheduler", Token: "s3cr3t"},
+			want: ErrInvalidNATSCredentials,
+		},
+		{
+			name: "invalid job: relative credentials file",
+			job:  &NATSJob{Servers: servers, Subject: "billing.invoices.close", CredentialsFile: "scheduler.creds"},
+			want: ErrInvalidNATSCredentials,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.job.Validate())
+		})
+	}
+}
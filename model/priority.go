@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+const (
+	// MinPriority and MaxPriority bound the priority of a job. Jobs with a higher priority are run first.
+	MinPriority = -100
+	MaxPriority = 100
+
+	// PriorityAgingInterval is how long a due job waits to be run before its priority is raised by one,
+	// so jobs with a low priority are run eventually even while jobs with a higher priority keep coming due.
+	PriorityAgingInterval = time.Minute
+)
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"net/url"
+)
+
+// ProxyConfig is the proxy the requests of an HTTP job are sent through, e.g. an egress proxy.
+// HTTP, HTTPS and SOCKS5 proxies are supported.
+type ProxyConfig struct {
+	URL      string `json:"url"`                // e.g., "http://egress.internal:3128"
+	Username string `json:"username,omitempty"` // e.g., "scheduler"
+	Password string `json:"password,omitempty"` // e.g., "s3cr3t"
+}
+
+// Validate validates a ProxyConfig struct. A nil ProxyConfig sends the requests directly.
+func (pc *ProxyConfig) Validate() error {
+	if pc == nil {
+		return nil
+	}
+
+	proxyURL, err := url.Parse(pc.URL)
+	if err != nil || proxyURL.Host == "" || proxyURL.User != nil {
+		return ErrInvalidProxy
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return ErrInvalidProxy
+	}
+
+	if pc.Password != "" && pc.Username == "" {
+		return ErrInvalidProxy
+	}
+
+	return nil
+}
+
+// ProxyURL returns the URL of the proxy with its credentials.
+func (pc *ProxyConfig) ProxyURL() (*url.URL, error) {
+	proxyURL, err := url.Parse(pc.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if pc.Username != "" {
+		proxyURL.User = url.UserPassword(pc.Username, pc.Password)
+	}
+
+	return proxyURL, nil
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"net/url"
+	"strings"
+)
+
+// RedirectMode is how an HTTP job follows the redirects of its endpoint.
+type RedirectMode string
+
+const (
+	RedirectModeFollow   RedirectMode = "follow"    // all redirects are followed (default)
+	RedirectModeNone     RedirectMode = "none"      // no redirect is followed, the redirect response is the job's response
+	RedirectModeSameHost RedirectMode = "same_host" // only redirects to the host of the job's URL are followed
+)
+
+const (
+	// DefaultMaxRedirects is how many redirects an HTTP job follows at most, unless its redirect policy sets its own limit.
+	// MaxRedirects bounds the limit a job can set.
+	DefaultMaxRedirects = 10
+	MaxRedirects        = 20
+)
+
+// RedirectPolicy is which redirects an HTTP job follows. A redirect that isn't followed, e.g. because it leads
+// to another host or because it would be one too many, ends the request: the redirect response is the job's
+// response, which fails the execution unless its status code is one of the job's valid response codes.
+type RedirectPolicy struct {
+	Mode    RedirectMode `json:"mode,omitempty"`     // "follow" (default), "none" or "same_host"
+	MaxHops int          `json:"max_hops,omitempty"` // how many redirects are followed at most (default 10, at most 20)
+}
+
+// RedirectHop is a redirect that was followed by an execution of an HTTP job.
+type RedirectHop struct {
+	StatusCode int    `json:"status_code"` // the status code of the redirect response, e.g. 302
+	Method     string `json:"method"`      // the method the redirect was followed with, e.g. "GET" after a POST was redirected with 302
+	URL        string `json:"url"`         // the URL the redirect led to, without its credentials
+}
+
+// Validate validates a RedirectPolicy struct. A nil RedirectPolicy follows all redirects.
+func (rp *RedirectPolicy) Validate() error {
+	if rp == nil {
+		return nil
+	}
+
+	switch rp.Mode {
+	case "", RedirectModeFollow, RedirectModeNone, RedirectModeSameHost:
+	default:
+		return ErrInvalidRedirectPolicy
+	}
+
+	if rp.MaxHops < 0 || rp.MaxHops > MaxRedirects {
+		return ErrInvalidRedirectPolicy
+	}
+
+	return nil
+}
+
+// EffectiveMaxHops returns how many redirects are followed at most.
+func (rp *RedirectPolicy) EffectiveMaxHops() int {
+	if rp == nil || rp.MaxHops == 0 {
+		return DefaultMaxRedirects
+	}
+
+	return rp.MaxHops
+}
+
+// Allows reports whether the redirect from a request to the URL origin to the URL to is followed,
+// after the given number of redirects were followed already.
+func (rp *RedirectPolicy) Allows(origin, to *url.URL, followed int) bool {
+	if followed >= rp.EffectiveMaxHops() {
+		return false
+	}
+
+	if rp == nil {
+		return true
+	}
+
+	switch rp.Mode {
+	case RedirectModeNone:
+		return false
+	case RedirectModeSameHost:
+		return strings.EqualFold(origin.Hostname(), to.Hostname())
+	default:
+		return true
+	}
+}
@@ -0,0 +1,145 @@
+package model
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultResponseBodyLimit is how many bytes of an HTTP response body are recorded with an execution,
+	// unless the job's response capture sets its own limit. MaxResponseBodyLimit bounds the limit a job can set.
+	DefaultResponseBodyLimit = 1024
+	MaxResponseBodyLimit     = 64 * 1024
+
+	// RedactedHeaderValue replaces the value of sensitive response headers.
+	RedactedHeaderValue = "[REDACTED]"
+)
+
+// defaultResponseHeaders are the response headers that are always recorded with an execution.
+var defaultResponseHeaders = []string{"Content-Type", "Content-Length", "Location", "Retry-After", "X-Request-Id"}
+
+// urlHeaders are the response headers whose values are URLs, which are recorded without their credentials,
+// query and fragment, see RedactURL.
+var urlHeaders = []string{"Location", "Content-Location"}
+
+// sensitiveHeaders are the response headers whose values are never recorded. Headers with a name that
+// contains one of sensitiveHeaderWords are treated as sensitive as well, e.g. "X-Api-Key".
+var (
+	sensitiveHeaders     = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	sensitiveHeaderWords = []string{"token", "secret", "key", "password", "session", "signature", "credential"}
+)
+
+// ResponseCapture selects what of an HTTP job's response is recorded with its executions.
+type ResponseCapture struct {
+	Headers   []string `json:"headers,omitempty"`    // recorded besides the default headers, e.g. ["X-RateLimit-Remaining"]
+	BodyLimit *int     `json:"body_limit,omitempty"` // bytes of the body that are recorded, 0 records none (default 1024, at most 65536)
+}
+
+// Validate validates a ResponseCapture struct. A nil response capture records the defaults.
+func (rc *ResponseCapture) Validate() error {
+	if rc == nil {
+		return nil
+	}
+
+	for _, header := range rc.Headers {
+		if header == "" {
+			return ErrInvalidResponseCapture
+		}
+	}
+
+	if rc.BodyLimit != nil && (*rc.BodyLimit < 0 || *rc.BodyLimit > MaxResponseBodyLimit) {
+		return ErrInvalidResponseCapture
+	}
+
+	return nil
+}
+
+// EffectiveBodyLimit returns how many bytes of the response body are recorded.
+func (rc *ResponseCapture) EffectiveBodyLimit() int {
+	if rc == nil || rc.BodyLimit == nil {
+		return DefaultResponseBodyLimit
+	}
+
+	return *rc.BodyLimit
+}
+
+// SelectHeaders returns the response headers that are recorded: the default ones and the ones selected
+// by the response capture, if the response has them. The values of sensitive headers are redacted.
+func (rc *ResponseCapture) SelectHeaders(header http.Header) map[string]string {
+	names := defaultResponseHeaders
+	if rc != nil {
+		names = append(append([]string{}, defaultResponseHeaders...), rc.Headers...)
+	}
+
+	selected := make(map[string]string)
+	for _, name := range names {
+		values := header.Values(name)
+		if len(values) == 0 {
+			continue
+		}
+
+		name = http.CanonicalHeaderKey(name)
+		selected[name] = strings.Join(values, ", ")
+		if sensitiveHeader(name) {
+			selected[name] = RedactedHeaderValue
+		} else if urlHeader(name) {
+			selected[name] = RedactURL(values[0])
+		}
+	}
+
+	return selected
+}
+
+func urlHeader(name string) bool {
+	for _, header := range urlHeaders {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactURL returns the URL without its credentials, query and fragment, which often carry tokens or signatures,
+// e.g. of a presigned URL a job is redirected to. A URL that can't be parsed is redacted as a whole.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return RedactedHeaderValue
+	}
+
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	return u.String()
+}
+
+func sensitiveHeader(name string) bool {
+	for _, header := range sensitiveHeaders {
+		if strings.EqualFold(name, header) {
+			return true
+		}
+	}
+
+	name = strings.ToLower(name)
+	for _, word := range sensitiveHeaderWords {
+		if strings.Contains(name, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HTTPResponse is the response to an execution of an HTTP job, as recorded with the execution.
+type HTTPResponse struct {
+	StatusCode    int               `json:"status_code"`              // e.g., 503
+	Headers       map[string]string `json:"headers,omitempty"`        // e.g., {"Retry-After": "120"}
+	Body          string            `json:"body,omitempty"`           // the start of the body, up to the job's body limit
+	BodyTruncated bool              `json:"body_truncated,omitempty"` // the body is longer than what was recorded
+	Redirects     []RedirectHop     `json:"redirects,omitempty"`      // the redirects that were followed to get the response
+}
@@ -0,0 +1,188 @@
+package model
+
+import (
+	"errors"
+	"net"
+	"time"
+)
+
+// FailureClass is the kind of failure a job execution ended with. A RetryPolicy uses it to
+// tell failures worth retrying from permanent ones.
+type FailureClass string
+
+const (
+	FailureClassHTTP4xx    FailureClass = "http_4xx"   // the endpoint answered with a 4xx status code
+	FailureClassHTTP5xx    FailureClass = "http_5xx"   // the endpoint answered with a 5xx status code
+	FailureClassAssertion  FailureClass = "assertion"  // the response failed one of the job's assertions
+	FailureClassConnection FailureClass = "connection" // the endpoint or broker could not be reached
+	FailureClassTimeout    FailureClass = "timeout"    // the execution exceeded the job's timeout
+	FailureClassOther      FailureClass = "other"      // any other failure
+)
+
+func (fc FailureClass) Valid() bool {
+	switch fc {
+	case FailureClassHTTP4xx, FailureClassHTTP5xx, FailureClassAssertion, FailureClassConnection, FailureClassTimeout, FailureClassOther:
+		return true
+	default:
+		return false
+	}
+}
+
+// ClassifyFailure returns the failure class of an error returned by an executor.
+func ClassifyFailure(err error) FailureClass {
+	if errors.Is(err, ErrExecutionTimedOut) {
+		return FailureClassTimeout
+	}
+
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		switch {
+		case statusErr.StatusCode >= 400 && statusErr.StatusCode < 500:
+			return FailureClassHTTP4xx
+		case statusErr.StatusCode >= 500 && statusErr.StatusCode < 600:
+			return FailureClassHTTP5xx
+		default:
+			return FailureClassOther
+		}
+	}
+
+	if errors.Is(err, ErrAssertionFailed) {
+		return FailureClassAssertion
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return FailureClassConnection
+	}
+
+	return FailureClassOther
+}
+
+// RetryPolicy controls how often and how quickly a failed job execution is retried.
+// Fields left at their zero value fall back to DefaultRetryPolicy.
+type RetryPolicy struct {
+	MaxAttempts     int            `json:"max_attempts"`                          // e.g., 5 (including the first attempt)
+	InitialInterval Duration       `json:"initial_interval" swaggertype:"string"` // e.g., "500ms"
+	MaxInterval     Duration       `json:"max_interval" swaggertype:"string"`     // e.g., "1m"
+	Multiplier      float64        `json:"multiplier"`                            // e.g., 1.5
+	RetryOn         []FailureClass `json:"retry_on"`                              // e.g., ["http_5xx", "connection"], empty retries every failure but http_4xx
+}
+
+// DefaultRetryPolicy is used for jobs that don't define their own retry policy. It retries every failure but
+// a 4xx response: a request the endpoint rejected is rejected again when it is sent again.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:     4,
+	InitialInterval: Duration(500 * time.Millisecond),
+	MaxInterval:     Duration(time.Minute),
+	Multiplier:      1.5,
+	RetryOn:         []FailureClass{FailureClassHTTP5xx, FailureClassAssertion, FailureClassConnection, FailureClassTimeout, FailureClassOther},
+}
+
+// Validate validates a RetryPolicy struct.
+func (rp *RetryPolicy) Validate() error {
+	if rp == nil {
+		return nil
+	}
+
+	if rp.MaxAttempts < 0 {
+		return ErrInvalidRetryMaxAttempts
+	}
+
+	if rp.InitialInterval < 0 || rp.MaxInterval < 0 {
+		return ErrInvalidRetryInterval
+	}
+
+	if rp.InitialInterval > 0 && rp.MaxInterval > 0 && rp.InitialInterval > rp.MaxInterval {
+		return ErrInvalidRetryInterval
+	}
+
+	if rp.Multiplier != 0 && rp.Multiplier < 1 {
+		return ErrInvalidRetryMultiplier
+	}
+
+	for _, class := range rp.RetryOn {
+		if !class.Valid() {
+			return ErrInvalidFailureClass
+		}
+	}
+
+	return nil
+}
+
+// Retryable reports whether the policy allows err to be retried.
+func (rp RetryPolicy) Retryable(err error) bool {
+	retryOn := rp.RetryOn
+	if len(retryOn) == 0 {
+		retryOn = DefaultRetryPolicy.RetryOn
+	}
+
+	class := ClassifyFailure(err)
+	for _, c := range retryOn {
+		if c == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EffectiveRetryPolicy returns the job's retry policy with unset fields filled in from DefaultRetryPolicy.
+func (j *Job) EffectiveRetryPolicy() RetryPolicy {
+	if j.RetryPolicy == nil {
+		return DefaultRetryPolicy
+	}
+
+	policy := *j.RetryPolicy
+
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = DefaultRetryPolicy.MaxAttempts
+	}
+
+	if policy.InitialInterval == 0 {
+		policy.InitialInterval = DefaultRetryPolicy.InitialInterval
+	}
+
+	if policy.MaxInterval == 0 {
+		policy.MaxInterval = DefaultRetryPolicy.MaxInterval
+	}
+
+	if policy.MaxInterval < policy.InitialInterval {
+		policy.MaxInterval = policy.InitialInterval
+	}
+
+	if policy.Multiplier == 0 {
+		policy.Multiplier = DefaultRetryPolicy.Multiplier
+	}
+
+	if len(policy.RetryOn) == 0 {
+		policy.RetryOn = DefaultRetryPolicy.RetryOn
+	}
+
+	return policy
+}
+
+// Interval returns how long to wait before retrying the given failed attempt (starting at 1).
+// The interval grows by the multiplier with each attempt and is capped at the max interval.
+func (rp RetryPolicy) Interval(attempt int) time.Duration {
+	interval := float64(rp.InitialInterval)
+	for i := 1; i < attempt && interval < float64(rp.MaxInterval); i++ {
+		interval *= rp.Multiplier
+	}
+
+	if interval > float64(rp.MaxInterval) {
+		return rp.MaxInterval.Duration()
+	}
+
+	return time.Duration(interval)
+}
+
+// ShouldRetry reports whether the job's current attempt, which failed with err, should be retried.
+func (j *Job) ShouldRetry(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	policy := j.EffectiveRetryPolicy()
+
+	return j.Attempt < policy.MaxAttempts && policy.Retryable(err)
+}
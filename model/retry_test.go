@@ -0,0 +1,163 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicyValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		want   error
+	}{
+		{
+			name:   "valid policy: not defined",
+			policy: nil,
+			want:   nil,
+		},
+		{
+			name: "valid policy",
+			policy: &RetryPolicy{
+				MaxAttempts:     5,
+				InitialInterval: Duration(time.Second),
+				MaxInterval:     Duration(time.Minute),
+				Multiplier:      2,
+				RetryOn:         []FailureClass{FailureClassHTTP5xx, FailureClassConnection},
+			},
+			want: nil,
+		},
+		{
+			name:   "invalid policy: negative max attempts",
+			policy: &RetryPolicy{MaxAttempts: -1},
+			want:   ErrInvalidRetryMaxAttempts,
+		},
+		{
+			name:   "invalid policy: initial interval greater than max interval",
+			policy: &RetryPolicy{InitialInterval: Duration(time.Minute), MaxInterval: Duration(time.Second)},
+			want:   ErrInvalidRetryInterval,
+		},
+		{
+			name:   "invalid policy: multiplier below 1",
+			policy: &RetryPolicy{Multiplier: 0.5},
+			want:   ErrInvalidRetryMultiplier,
+		},
+		{
+			name:   "invalid policy: unknown failure class",
+			policy: &RetryPolicy{RetryOn: []FailureClass{"http_3xx"}},
+			want:   ErrInvalidFailureClass,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.policy.Validate()
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestClassifyFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want FailureClass
+	}{
+		{"4xx status code", &HTTPStatusError{StatusCode: 404}, FailureClassHTTP4xx},
+		{"5xx status code", &HTTPStatusError{StatusCode: 503}, FailureClassHTTP5xx},
+		{"3xx status code", &HTTPStatusError{StatusCode: 301}, FailureClassOther},
+		{"wrapped status code", fmt.Errorf("request failed: %w", &HTTPStatusError{StatusCode: 500}), FailureClassHTTP5xx},
+		{"connection error", fmt.Errorf("failed to connect: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), FailureClassConnection},
+		{"timeout", fmt.Errorf("%w after 1s: %v", ErrExecutionTimedOut, &net.OpError{Op: "read", Err: errors.New("i/o timeout")}), FailureClassTimeout},
+		{"other error", errors.New("failed to decode body"), FailureClassOther},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ClassifyFailure(tc.err))
+		})
+	}
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	policy := RetryPolicy{RetryOn: []FailureClass{FailureClassHTTP5xx, FailureClassConnection}}
+
+	assert.True(t, policy.Retryable(&HTTPStatusError{StatusCode: 502}))
+	assert.False(t, policy.Retryable(&HTTPStatusError{StatusCode: 400}))
+	assert.False(t, policy.Retryable(errors.New("other error")))
+
+	// without retry_on every failure but a 4xx response is retried
+	assert.False(t, RetryPolicy{}.Retryable(&HTTPStatusError{StatusCode: 400}))
+	assert.True(t, RetryPolicy{}.Retryable(&HTTPStatusError{StatusCode: 503}))
+	assert.True(t, RetryPolicy{}.Retryable(errors.New("other error")))
+}
+
+func TestJobEffectiveRetryPolicy(t *testing.T) {
+	j := &Job{}
+	assert.Equal(t, DefaultRetryPolicy, j.EffectiveRetryPolicy())
+
+	j.RetryPolicy = &RetryPolicy{MaxAttempts: 10, InitialInterval: Duration(2 * time.Minute)}
+	policy := j.EffectiveRetryPolicy()
+
+	assert.Equal(t, 10, policy.MaxAttempts)
+	assert.Equal(t, Duration(2*time.Minute), policy.InitialInterval)
+	assert.Equal(t, Duration(2*time.Minute), policy.MaxInterval)
+	assert.Equal(t, DefaultRetryPolicy.Multiplier, policy.Multiplier)
+	assert.Equal(t, DefaultRetryPolicy.RetryOn, policy.RetryOn)
+}
+
+func TestRetryPolicyJSON(t *testing.T) {
+	var policy RetryPolicy
+	err := json.Unmarshal([]byte(`{"max_attempts": 3, "initial_interval": "1s", "max_interval": "1m30s", "retry_on": ["http_5xx"]}`), &policy)
+	require.NoError(t, err)
+
+	assert.Equal(t, Duration(time.Second), policy.InitialInterval)
+	assert.Equal(t, Duration(90*time.Second), policy.MaxInterval)
+
+	data, err := json.Marshal(policy)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"max_attempts": 3, "initial_interval": "1s", "max_interval": "1m30s", "multiplier": 0, "retry_on": ["http_5xx"]}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"initial_interval": "soon"}`), &policy)
+	assert.Error(t, err)
+}
+
+func TestRetryPolicyInterval(t *testing.T) {
+	policy := RetryPolicy{
+		InitialInterval: Duration(time.Second),
+		MaxInterval:     Duration(10 * time.Second),
+		Multiplier:      2,
+	}
+
+	assert.Equal(t, time.Second, policy.Interval(1))
+	assert.Equal(t, 2*time.Second, policy.Interval(2))
+	assert.Equal(t, 8*time.Second, policy.Interval(4))
+	assert.Equal(t, 10*time.Second, policy.Interval(5))
+	assert.Equal(t, 10*time.Second, policy.Interval(100))
+}
+
+func TestJobShouldRetry(t *testing.T) {
+	j := &Job{
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, RetryOn: []FailureClass{FailureClassHTTP5xx}},
+		Attempt:     1,
+	}
+
+	assert.False(t, j.ShouldRetry(nil))
+	assert.True(t, j.ShouldRetry(&HTTPStatusError{StatusCode: 500}))
+	assert.False(t, j.ShouldRetry(&HTTPStatusError{StatusCode: 400}))
+
+	j.Attempt = 3
+	assert.False(t, j.ShouldRetry(&HTTPStatusError{StatusCode: 500}))
+
+	// the default policy keeps a 4xx response failed
+	j = &Job{Attempt: 1}
+	assert.False(t, j.ShouldRetry(&HTTPStatusError{StatusCode: 404}))
+	assert.True(t, j.ShouldRetry(&HTTPStatusError{StatusCode: 502}))
+}
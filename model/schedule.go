@@ -0,0 +1,85 @@
+package model
+
+import (
+	"time"
+	_ "time/tzdata" // time zones of jobs must be known even on hosts without a time zone database
+
+	"github.com/robfig/cron/v3"
+)
+
+// allHours is the bitmask of a cron hour field that matches every hour of the day.
+const allHours = 1<<24 - 1
+
+// Schedule returns the job's cron schedule, evaluated in the job's time zone (the local time zone when not set).
+func (j *Job) Schedule() (cron.Schedule, error) {
+	schedule, err := cron.ParseStandard(j.CronSchedule.String)
+	if err != nil {
+		return nil, ErrInvalidCronSchedule
+	}
+
+	spec, ok := schedule.(*cron.SpecSchedule)
+	if !ok {
+		// e.g. "@every 1h", which doesn't depend on the time zone
+		return schedule, nil
+	}
+
+	if j.Timezone.Valid {
+		loc, err := time.LoadLocation(j.Timezone.String)
+		if err != nil {
+			return nil, ErrInvalidTimezone
+		}
+
+		spec.Location = loc
+	}
+
+	return &zonedSchedule{spec: spec}, nil
+}
+
+// zonedSchedule is a cron schedule that handles daylight saving time transitions of its time zone.
+//
+// A schedule with fixed hours, e.g. "every day at 02:30", follows the wall clock: it runs once on the day the
+// clocks are set back and the 02:30 occurs twice, and on the day the clocks are set forward and 02:30 doesn't
+// exist it runs at the corresponding time after the transition (03:30). A schedule that matches every hour,
+// e.g. "every 15 minutes", follows the elapsed time instead, so it keeps running during repeated hours.
+type zonedSchedule struct {
+	spec *cron.SpecSchedule
+}
+
+func (s *zonedSchedule) Next(t time.Time) time.Time {
+	if s.spec.Hour&allHours == allHours {
+		return s.spec.Next(t)
+	}
+
+	// Find the next time on the wall clock, without the time zone's transitions, by evaluating the
+	// schedule in UTC, and convert it back to the time zone.
+	wallSpec := *s.spec
+	wallSpec.Location = time.UTC
+
+	loc := s.spec.Location
+	wall := toWallClock(t.In(loc))
+
+	for {
+		wall = wallSpec.Next(wall)
+		if wall.IsZero() {
+			return time.Time{}
+		}
+
+		next := fromWallClock(wall, loc)
+
+		// a repeated wall clock time can map back to before t, in which case it already occurred
+		if next.After(t) {
+			return next.In(t.Location())
+		}
+	}
+}
+
+// toWallClock returns the UTC time with the same wall clock as t.
+func toWallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+// fromWallClock returns the time in loc with the same wall clock as the UTC time wall. A wall clock time that
+// doesn't exist in loc is moved forward by the length of the transition.
+func fromWallClock(wall time.Time, loc *time.Location) time.Time {
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), loc)
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+)
+
+func nextRuns(t *testing.T, j *Job, from time.Time, n int) []time.Time {
+	schedule, err := j.Schedule()
+	require.NoError(t, err)
+
+	var runs []time.Time
+	for next := from; len(runs) < n; {
+		next = schedule.Next(next)
+		runs = append(runs, next)
+	}
+
+	return runs
+}
+
+func TestJobScheduleTimezone(t *testing.T) {
+	j := &Job{CronSchedule: null.StringFrom("0 9 * * *"), Timezone: null.StringFrom("America/New_York")}
+
+	runs := nextRuns(t, j, time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), 1)
+	assert.Equal(t, time.Date(2023, 6, 1, 13, 0, 0, 0, time.UTC), runs[0].UTC())
+
+	j.Timezone = null.StringFrom("Mars/Olympus_Mons")
+	_, err := j.Schedule()
+	assert.Equal(t, ErrInvalidTimezone, err)
+}
+
+func TestJobScheduleDST(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Ljubljana")
+	require.NoError(t, err)
+
+	daily := &Job{CronSchedule: null.StringFrom("30 2 * * *"), Timezone: null.StringFrom("Europe/Ljubljana")}
+	halfHourly := &Job{CronSchedule: null.StringFrom("*/30 * * * *"), Timezone: null.StringFrom("Europe/Ljubljana")}
+
+	t.Run("skipped hour", func(t *testing.T) {
+		// on 2023-03-26 the clocks go from 02:00 CET forward to 03:00 CEST, so 02:30 doesn't exist
+		runs := nextRuns(t, daily, time.Date(2023, 3, 25, 12, 0, 0, 0, loc), 3)
+
+		assert.Equal(t, []time.Time{
+			time.Date(2023, 3, 26, 3, 30, 0, 0, loc), // the run is moved past the transition
+			time.Date(2023, 3, 27, 2, 30, 0, 0, loc),
+			time.Date(2023, 3, 28, 2, 30, 0, 0, loc),
+		}, runs)
+
+		runs = nextRuns(t, halfHourly, time.Date(2023, 3, 26, 1, 0, 0, 0, loc), 3)
+
+		assert.Equal(t, []time.Time{
+			time.Date(2023, 3, 26, 1, 30, 0, 0, loc),
+			time.Date(2023, 3, 26, 3, 0, 0, 0, loc), // one hour later
+			time.Date(2023, 3, 26, 3, 30, 0, 0, loc),
+		}, runs)
+	})
+
+	t.Run("repeated hour", func(t *testing.T) {
+		// on 2023-10-29 the clocks go from 03:00 CEST back to 02:00 CET, so 02:30 occurs twice
+		runs := nextRuns(t, daily, time.Date(2023, 10, 28, 12, 0, 0, 0, loc), 2)
+
+		assert.Equal(t, 29, runs[0].In(loc).Day())
+		assert.Equal(t, 2, runs[0].In(loc).Hour())
+		assert.Equal(t, time.Date(2023, 10, 30, 2, 30, 0, 0, loc), runs[1]) // the job runs once a day
+
+		// a run in the first occurrence of the hour isn't repeated in the second one
+		cest := time.Date(2023, 10, 29, 0, 30, 0, 0, time.UTC) // 02:30 CEST
+		runs = nextRuns(t, daily, cest, 1)
+		assert.Equal(t, time.Date(2023, 10, 30, 2, 30, 0, 0, loc), runs[0].In(loc))
+
+		// a schedule that matches every hour keeps running during the repeated hour
+		runs = nextRuns(t, halfHourly, time.Date(2023, 10, 29, 0, 0, 0, 0, time.UTC), 4)
+
+		assert.Equal(t, []time.Time{
+			time.Date(2023, 10, 29, 0, 30, 0, 0, time.UTC), // 02:30 CEST
+			time.Date(2023, 10, 29, 1, 0, 0, 0, time.UTC),  // 02:00 CET
+			time.Date(2023, 10, 29, 1, 30, 0, 0, time.UTC), // 02:30 CET
+			time.Date(2023, 10, 29, 2, 0, 0, 0, time.UTC),  // 03:00 CET
+		}, mapUTC(runs))
+	})
+}
+
+func mapUTC(times []time.Time) []time.Time {
+	utc := make([]time.Time, len(times))
+	for i, t := range times {
+		utc[i] = t.UTC()
+	}
+	return utc
+}
@@ -0,0 +1,273 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxTemplateOutput bounds the size of a rendered template, so a template can't exhaust the runner's memory.
+const maxTemplateOutput = 1 << 20
+
+// TemplateData holds the variables available to the templates of a job's payload, e.g. {{ .ScheduledAt }}.
+type TemplateData struct {
+	JobID       uuid.UUID  // the job's ID
+	RunID       uuid.UUID  // the ID of the run, shared by all of its attempts
+	ExecutionID uuid.UUID  // the ID of this execution, unique for every attempt
+	Attempt     int        // the attempt number of the run, starting at 1
+	Trigger     RunTrigger // what started the run, e.g. "scheduled" or "manual"
+	ScheduledAt time.Time  // when the run was due (the scheduled fire time)
+	StartedAt   time.Time  // when this execution started
+}
+
+// templateFuncs are the functions available to templates besides the built-in ones of text/template.
+// None of them have side effects or access anything outside of the template's data.
+var templateFuncs = template.FuncMap{
+	// json encodes the value as JSON, e.g. to embed it in a JSON body: {"job": {{ json .JobID }}}
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	// formatTime formats the time with the Go layout, e.g. {{ formatTime .ScheduledAt "2006-01-02" }}
+	"formatTime": func(t time.Time, layout string) string {
+		return t.Format(layout)
+	},
+	// rfc3339 formats the time in RFC 3339, in UTC
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	// unix returns the time as Unix seconds
+	"unix": func(t time.Time) int64 {
+		return t.Unix()
+	},
+	"base64": func(s string) string {
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// TemplateData returns the template variables of the job's current execution, started at time startedAt.
+func (j *Job) TemplateData(startedAt time.Time) TemplateData {
+	return TemplateData{
+		JobID:       j.ID,
+		RunID:       j.RunID.UUID,
+		ExecutionID: j.ExecutionID,
+		Attempt:     j.Attempt,
+		Trigger:     j.RunTrigger,
+		ScheduledAt: j.ScheduledAt.Time,
+		StartedAt:   startedAt,
+	}
+}
+
+// RenderTemplate renders the template text with the given data. Templates that loop or call templates are
+// rejected, see checkTemplateActions.
+func RenderTemplate(text string, data TemplateData) (string, error) {
+	tmpl, err := template.New("").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	if tmpl.Tree != nil {
+		if err := checkTemplateActions(tmpl.Tree.Root); err != nil {
+			return "", err
+		}
+	}
+
+	out := &limitedBuilder{limit: maxTemplateOutput}
+	if err := tmpl.Execute(out, data); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
+
+// validateTemplate checks that the template text of the given field parses and renders.
+func validateTemplate(field, text string) error {
+	sample := TemplateData{
+		JobID:       uuid.New(),
+		RunID:       uuid.New(),
+		ExecutionID: uuid.New(),
+		Attempt:     1,
+		Trigger:     RunTriggerScheduled,
+		ScheduledAt: time.Now(),
+		StartedAt:   time.Now(),
+	}
+
+	if _, err := RenderTemplate(text, sample); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrInvalidTemplate, field, err)
+	}
+
+	return nil
+}
+
+// Render returns a copy of the HTTP job with its URL, headers and body rendered, if the job is a template.
+func (httpJob *HTTPJob) Render(data TemplateData) (*HTTPJob, error) {
+	if !httpJob.Template {
+		return httpJob, nil
+	}
+
+	rendered := *httpJob
+
+	var err error
+	if rendered.URL, err = RenderTemplate(httpJob.URL, data); err != nil {
+		return nil, fmt.Errorf("failed to render url: %w", err)
+	}
+
+	if rendered.Headers, err = renderHeaders(httpJob.Headers, data); err != nil {
+		return nil, err
+	}
+
+	if httpJob.Body.Valid {
+		if rendered.Body.String, err = RenderTemplate(httpJob.Body.String, data); err != nil {
+			return nil, fmt.Errorf("failed to render body: %w", err)
+		}
+	}
+
+	return &rendered, nil
+}
+
+// Render returns a copy of the AMQP job with its routing key, headers and body rendered, if the job is a template.
+func (amqpJob *AMQPJob) Render(data TemplateData) (*AMQPJob, error) {
+	if !amqpJob.Template {
+		return amqpJob, nil
+	}
+
+	rendered := *amqpJob
+
+	var err error
+	if rendered.RoutingKey, err = RenderTemplate(amqpJob.RoutingKey, data); err != nil {
+		return nil, fmt.Errorf("failed to render routing key: %w", err)
+	}
+
+	// only string header values are templates
+	rendered.Headers = make(map[string]interface{}, len(amqpJob.Headers))
+	for key, value := range amqpJob.Headers {
+		if s, ok := value.(string); ok {
+			if value, err = RenderTemplate(s, data); err != nil {
+				return nil, fmt.Errorf("failed to render header %s: %w", key, err)
+			}
+		}
+		rendered.Headers[key] = value
+	}
+
+	if rendered.Body, err = RenderTemplate(amqpJob.Body, data); err != nil {
+		return nil, fmt.Errorf("failed to render body: %w", err)
+	}
+
+	return &rendered, nil
+}
+
+func renderHeaders(headers map[string]string, data TemplateData) (map[string]string, error) {
+	rendered := make(map[string]string, len(headers))
+	for key, value := range headers {
+		value, err := RenderTemplate(value, data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render header %s: %w", key, err)
+		}
+		rendered[key] = value
+	}
+
+	return rendered, nil
+}
+
+// validateTemplates checks the templates of the HTTP job, if it is a template.
+func (httpJob *HTTPJob) validateTemplates() error {
+	if !httpJob.Template {
+		return nil
+	}
+
+	if err := validateTemplate("url", httpJob.URL); err != nil {
+		return err
+	}
+
+	for key, value := range httpJob.Headers {
+		if err := validateTemplate("header "+key, value); err != nil {
+			return err
+		}
+	}
+
+	return validateTemplate("body", httpJob.Body.String)
+}
+
+// validateTemplates checks the templates of the AMQP job, if it is a template.
+func (amqpJob *AMQPJob) validateTemplates() error {
+	if !amqpJob.Template {
+		return nil
+	}
+
+	// a base64 encoded body is binary, it can't be a template
+	if amqpJob.BodyEncoding != nil {
+		return fmt.Errorf("%w: body: a body with an encoding can't be a template", ErrInvalidTemplate)
+	}
+
+	if err := validateTemplate("routing key", amqpJob.RoutingKey); err != nil {
+		return err
+	}
+
+	for key, value := range amqpJob.Headers {
+		if s, ok := value.(string); ok {
+			if err := validateTemplate("header "+key, s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return validateTemplate("body", amqpJob.Body)
+}
+
+var errTemplateOutputTooLarge = errors.New("rendered template is too large")
+
+var errTemplateLoop = errors.New("range, template and block actions aren't supported")
+
+// checkTemplateActions returns errTemplateLoop if the template has a range action, which can loop over an int
+// for as long as it likes while writing nothing, or calls a template, which can call itself. The output limit
+// doesn't bound the run time of either, and nothing in TemplateData can be ranged over anyway.
+func checkTemplateActions(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkTemplateActions(child); err != nil {
+				return err
+			}
+		}
+	case *parse.IfNode:
+		if err := checkTemplateActions(n.List); err != nil {
+			return err
+		}
+		return checkTemplateActions(n.ElseList)
+	case *parse.WithNode:
+		if err := checkTemplateActions(n.List); err != nil {
+			return err
+		}
+		return checkTemplateActions(n.ElseList)
+	case *parse.RangeNode, *parse.TemplateNode:
+		return errTemplateLoop
+	}
+
+	return nil
+}
+
+// limitedBuilder is a strings.Builder that fails once more than limit bytes are written to it.
+type limitedBuilder struct {
+	strings.Builder
+	limit int
+}
+
+func (b *limitedBuilder) Write(p []byte) (int, error) {
+	if b.Len()+len(p) > b.limit {
+		return 0, errTemplateOutputTooLarge
+	}
+
+	return b.Builder.Write(p)
+}
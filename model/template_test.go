@@ -0,0 +1,171 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	scheduledAt := time.Date(2023, 6, 1, 12, 30, 0, 0, time.UTC)
+
+	data := TemplateData{
+		JobID:       uuid.New(),
+		RunID:       uuid.New(),
+		ExecutionID: uuid.New(),
+		Attempt:     2,
+		Trigger:     RunTriggerScheduled,
+		ScheduledAt: scheduledAt,
+		StartedAt:   scheduledAt.Add(time.Second),
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "plain text",
+			text: "Hello, world!",
+			want: "Hello, world!",
+		},
+		{
+			name: "variables",
+			text: "{{ .JobID }}/{{ .Attempt }}/{{ .Trigger }}",
+			want: data.JobID.String() + "/2/scheduled",
+		},
+		{
+			name: "time functions",
+			text: `{{ rfc3339 .ScheduledAt }} {{ formatTime .ScheduledAt "2006-01-02" }} {{ unix .ScheduledAt }}`,
+			want: "2023-06-01T12:30:00Z 2023-06-01 1685622600",
+		},
+		{
+			name: "json",
+			text: `{"execution_id": {{ json .ExecutionID }}}`,
+			want: `{"execution_id": "` + data.ExecutionID.String() + `"}`,
+		},
+		{
+			name: "string functions",
+			text: `{{ upper "a" }}{{ lower "B" }} {{ base64 "foo" }}`,
+			want: "Ab Zm9v",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RenderTemplate(tc.text, data)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	t.Run("unknown variable", func(t *testing.T) {
+		_, err := RenderTemplate("{{ .Secret }}", data)
+		assert.Error(t, err)
+	})
+
+	t.Run("output too large", func(t *testing.T) {
+		_, err := RenderTemplate(`{{ .JobID }}`+strings.Repeat("x", maxTemplateOutput), data)
+		assert.ErrorIs(t, err, errTemplateOutputTooLarge)
+	})
+
+	loops := map[string]string{
+		"range over an int":     `{{ range 300000000 }}{{ end }}x`,
+		"range in a branch":     `{{ if .JobID }}{{ with .RunID }}{{ range unix $.ScheduledAt }}{{ end }}{{ end }}{{ end }}`,
+		"recursive template":    `{{ define "a" }}{{ template "a" . }}{{ template "a" . }}{{ end }}{{ template "a" . }}`,
+		"block":                 `{{ block "a" . }}{{ .JobID }}{{ end }}`,
+		"template in else list": `{{ if .Attempt }}{{ else }}{{ template "a" }}{{ end }}{{ define "a" }}{{ end }}`,
+	}
+	for name, text := range loops {
+		text := text
+		t.Run(name, func(t *testing.T) {
+			_, err := RenderTemplate(text, data)
+			assert.ErrorIs(t, err, errTemplateLoop)
+		})
+	}
+}
+
+func TestTemplateValidate(t *testing.T) {
+	httpJob := func(body string) *HTTPJob {
+		return &HTTPJob{
+			URL:      "https://example.com/{{ .JobID }}",
+			Method:   "POST",
+			Body:     null.StringFrom(body),
+			Auth:     Auth{Type: AuthTypeNone},
+			Template: true,
+		}
+	}
+
+	assert.NoError(t, httpJob(`{"at": "{{ rfc3339 .ScheduledAt }}"}`).Validate())
+	assert.ErrorIs(t, httpJob(`{"at": "{{ .ScheduledAt"}`).Validate(), ErrInvalidTemplate)
+	assert.ErrorIs(t, httpJob(`{{ env "HOME" }}`).Validate(), ErrInvalidTemplate)
+	assert.ErrorIs(t, httpJob(`{{ range 300000000 }}{{ end }}x`).Validate(), ErrInvalidTemplate)
+
+	// the body is sent as is if the job is not a template
+	notTemplate := httpJob(`{{ .ScheduledAt`)
+	notTemplate.Template = false
+	assert.NoError(t, notTemplate.Validate())
+
+	encoding := BodyEncodingBase64
+	amqpJob := &AMQPJob{
+		Exchange:     "exchange",
+		RoutingKey:   "jobs.{{ .Trigger }}",
+		Headers:      map[string]interface{}{"x-run": "{{ .RunID", "x-delay": 1000},
+		Body:         "Hello",
+		BodyEncoding: &encoding,
+		Template:     true,
+	}
+	assert.ErrorIs(t, amqpJob.Validate(), ErrInvalidTemplate)
+
+	amqpJob.BodyEncoding = nil
+	assert.ErrorIs(t, amqpJob.Validate(), ErrInvalidTemplate)
+
+	amqpJob.Headers["x-run"] = "{{ .RunID }}"
+	assert.NoError(t, amqpJob.Validate())
+}
+
+func TestTemplateRender(t *testing.T) {
+	job := &Job{
+		ID:          uuid.New(),
+		RunID:       uuid.NullUUID{UUID: uuid.New(), Valid: true},
+		ExecutionID: uuid.New(),
+		Attempt:     1,
+		RunTrigger:  RunTriggerManual,
+		ScheduledAt: null.TimeFrom(time.Now()),
+		HTTPJob: &HTTPJob{
+			URL:      "https://example.com/jobs/{{ .JobID }}",
+			Method:   "POST",
+			Headers:  map[string]string{"Idempotency-Key": "{{ .RunID }}"},
+			Body:     null.StringFrom(`{"attempt": {{ .Attempt }}}`),
+			Template: true,
+		},
+		AMQPJob: &AMQPJob{
+			RoutingKey: "jobs.{{ .Trigger }}",
+			Headers:    map[string]interface{}{"x-execution": "{{ .ExecutionID }}", "x-delay": 1000},
+			Body:       "{{ .Attempt }}",
+			Template:   true,
+		},
+	}
+
+	data := job.TemplateData(time.Now())
+
+	httpJob, err := job.HTTPJob.Render(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/jobs/"+job.ID.String(), httpJob.URL)
+	assert.Equal(t, job.RunID.UUID.String(), httpJob.Headers["Idempotency-Key"])
+	assert.Equal(t, `{"attempt": 1}`, httpJob.Body.String)
+
+	// the job itself is left as it is
+	assert.Equal(t, "{{ .RunID }}", job.HTTPJob.Headers["Idempotency-Key"])
+
+	amqpJob, err := job.AMQPJob.Render(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "jobs.manual", amqpJob.RoutingKey)
+	assert.Equal(t, job.ExecutionID.String(), amqpJob.Headers["x-execution"])
+	assert.Equal(t, 1000, amqpJob.Headers["x-delay"])
+	assert.Equal(t, "1", amqpJob.Body)
+}
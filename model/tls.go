@@ -0,0 +1,90 @@
+package model
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"path/filepath"
+)
+
+// tlsVersions are the TLS versions an HTTP job can require at least.
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSConfig configures the TLS connections of an HTTP job, e.g. to call a partner that requires a client
+// certificate (mutual TLS) or whose server certificate is issued by a private CA.
+//
+// The certificates and the key are either given PEM encoded, or as the paths of files on the runners, e.g.
+// of mounted secrets, which keeps the key out of the database. Files are read again for every execution,
+// so rotated certificates are picked up without restarting the runners.
+type TLSConfig struct {
+	ClientCert     string `json:"client_cert,omitempty"`      // PEM encoded client certificate, followed by its intermediates
+	ClientKey      string `json:"client_key,omitempty"`       // PEM encoded private key of the client certificate
+	ClientCertFile string `json:"client_cert_file,omitempty"` // e.g., "/etc/scheduler/tls/partner.crt"
+	ClientKeyFile  string `json:"client_key_file,omitempty"`  // e.g., "/etc/scheduler/tls/partner.key"
+	CACert         string `json:"ca_cert,omitempty"`          // PEM encoded CA bundle the server certificate is verified against, instead of the system's
+	CACertFile     string `json:"ca_cert_file,omitempty"`     // e.g., "/etc/scheduler/tls/partner-ca.pem"
+	ServerName     string `json:"server_name,omitempty"`      // the name the server certificate is verified for, instead of the URL's host
+	MinVersion     string `json:"min_version,omitempty"`      // "1.0", "1.1", "1.2" (default) or "1.3"
+}
+
+// Validate validates a TLSConfig struct. A nil TLSConfig uses the runner's default TLS configuration.
+// The files a TLSConfig refers to are only read by the runners, so only their paths are validated.
+func (c *TLSConfig) Validate() error {
+	if c == nil {
+		return nil
+	}
+
+	if c.ClientCert != "" && c.ClientCertFile != "" {
+		return fmt.Errorf("%w: only one of client_cert and client_cert_file can be set", ErrInvalidTLSConfig)
+	}
+
+	if c.ClientKey != "" && c.ClientKeyFile != "" {
+		return fmt.Errorf("%w: only one of client_key and client_key_file can be set", ErrInvalidTLSConfig)
+	}
+
+	if c.CACert != "" && c.CACertFile != "" {
+		return fmt.Errorf("%w: only one of ca_cert and ca_cert_file can be set", ErrInvalidTLSConfig)
+	}
+
+	hasCert := c.ClientCert != "" || c.ClientCertFile != ""
+	hasKey := c.ClientKey != "" || c.ClientKeyFile != ""
+	if hasCert != hasKey {
+		return fmt.Errorf("%w: a client certificate must be set together with its key", ErrInvalidTLSConfig)
+	}
+
+	for field, path := range map[string]string{"client_cert_file": c.ClientCertFile, "client_key_file": c.ClientKeyFile, "ca_cert_file": c.CACertFile} {
+		if path != "" && !filepath.IsAbs(path) {
+			return fmt.Errorf("%w: %s must be an absolute path", ErrInvalidTLSConfig, field)
+		}
+	}
+
+	if c.ClientCert != "" && c.ClientKey != "" {
+		if _, err := tls.X509KeyPair([]byte(c.ClientCert), []byte(c.ClientKey)); err != nil {
+			return fmt.Errorf("%w: invalid client certificate: %v", ErrInvalidTLSConfig, err)
+		}
+	}
+
+	if c.CACert != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(c.CACert)) {
+		return fmt.Errorf("%w: ca_cert has no valid PEM encoded certificates", ErrInvalidTLSConfig)
+	}
+
+	if _, ok := tlsVersions[c.MinVersion]; c.MinVersion != "" && !ok {
+		return fmt.Errorf("%w: min_version must be either 1.0, 1.1, 1.2 or 1.3", ErrInvalidTLSConfig)
+	}
+
+	return nil
+}
+
+// Version returns the minimum TLS version, 0 if the default applies.
+func (c *TLSConfig) Version() uint16 {
+	if c == nil {
+		return 0
+	}
+
+	return tlsVersions[c.MinVersion]
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// selfSignedCertificate returns a PEM encoded self-signed certificate and its key.
+func selfSignedCertificate(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "scheduler"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestTLSConfig_Validate(t *testing.T) {
+	cert, key := selfSignedCertificate(t)
+	_, otherKey := selfSignedCertificate(t)
+
+	tests := []struct {
+		name    string
+		config  *TLSConfig
+		wantErr bool
+	}{
+		{name: "nil", config: nil},
+		{name: "inline client certificate and CA", config: &TLSConfig{ClientCert: cert, ClientKey: key, CACert: cert, ServerName: "partner.internal", MinVersion: "1.3"}},
+		{name: "files", config: &TLSConfig{ClientCertFile: "/etc/tls/client.crt", ClientKeyFile: "/etc/tls/client.key", CACertFile: "/etc/tls/ca.pem"}},
+		{name: "inline certificate with key file", config: &TLSConfig{ClientCert: cert, ClientKeyFile: "/etc/tls/client.key"}},
+		{name: "certificate without key", config: &TLSConfig{ClientCert: cert}, wantErr: true},
+		{name: "key without certificate", config: &TLSConfig{ClientKeyFile: "/etc/tls/client.key"}, wantErr: true},
+		{name: "inline certificate and file", config: &TLSConfig{ClientCert: cert, ClientCertFile: "/etc/tls/client.crt", ClientKey: key}, wantErr: true},
+		{name: "inline CA and file", config: &TLSConfig{CACert: cert, CACertFile: "/etc/tls/ca.pem"}, wantErr: true},
+		{name: "key doesn't match the certificate", config: &TLSConfig{ClientCert: cert, ClientKey: otherKey}, wantErr: true},
+		{name: "invalid CA", config: &TLSConfig{CACert: "not a certificate"}, wantErr: true},
+		{name: "relative path", config: &TLSConfig{CACertFile: "ca.pem"}, wantErr: true},
+		{name: "invalid min version", config: &TLSConfig{MinVersion: "1.4"}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.config.Validate()
+			if tc.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidTLSConfig)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
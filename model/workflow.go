@@ -0,0 +1,353 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// WorkflowCondition tells which outcome of an upstream node lets a downstream node run.
+type WorkflowCondition string
+
+const (
+	WorkflowConditionSuccess WorkflowCondition = "success" // the upstream node succeeded
+	WorkflowConditionFailure WorkflowCondition = "failure" // the upstream node failed
+	WorkflowConditionAlways  WorkflowCondition = "always"  // the upstream node finished, whatever its outcome
+)
+
+func (wc WorkflowCondition) Valid() bool {
+	switch wc {
+	case WorkflowConditionSuccess, WorkflowConditionFailure, WorkflowConditionAlways:
+		return true
+	default:
+		return false
+	}
+}
+
+// swagger:model Workflow
+type Workflow struct {
+	ID    uuid.UUID      `json:"id"`
+	Name  string         `json:"name"`
+	Nodes []WorkflowNode `json:"nodes"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WorkflowNode is a step of a workflow, which runs an existing job.
+type WorkflowNode struct {
+	Name      string               `json:"name"`       // e.g., "export_sessions"
+	JobID     uuid.UUID            `json:"job_id"`     // the job that is run by the node
+	DependsOn []WorkflowDependency `json:"depends_on"` // the node runs once all of its dependencies are met
+}
+
+// WorkflowDependency is an edge of a workflow, from an upstream node to the node that depends on it.
+type WorkflowDependency struct {
+	Node      string            `json:"node"`                // name of the upstream node
+	Condition WorkflowCondition `json:"condition,omitempty"` // e.g., "success" (default), "failure", "always"
+}
+
+// EffectiveCondition returns the dependency's condition, or success if the dependency doesn't define it.
+func (d WorkflowDependency) EffectiveCondition() WorkflowCondition {
+	if d.Condition == "" {
+		return WorkflowConditionSuccess
+	}
+
+	return d.Condition
+}
+
+// swagger:model WorkflowCreate
+type WorkflowCreate struct {
+	// Name of the workflow, e.g. "billing close"
+	Name string `json:"name"`
+
+	// Nodes of the workflow. Every node runs an existing job, at most one node per job, once the nodes it
+	// depends on have finished with the outcome its dependencies require.
+	Nodes []WorkflowNode `json:"nodes"`
+}
+
+func (w *WorkflowCreate) ToWorkflow() *Workflow {
+	return &Workflow{
+		ID:        uuid.New(),
+		Name:      w.Name,
+		Nodes:     w.Nodes,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+// Validate validates a Workflow struct. The nodes must form a directed acyclic graph.
+func (w *Workflow) Validate() error {
+	if w.ID == uuid.Nil {
+		return ErrInvalidWorkflowID
+	}
+
+	if w.Name == "" {
+		return ErrEmptyWorkflowName
+	}
+
+	if len(w.Nodes) == 0 {
+		return ErrEmptyWorkflowNodes
+	}
+
+	nodes := make(map[string]WorkflowNode, len(w.Nodes))
+	jobs := make(map[uuid.UUID]bool, len(w.Nodes))
+
+	for _, node := range w.Nodes {
+		if node.Name == "" || node.JobID == uuid.Nil {
+			return ErrInvalidWorkflowNode
+		}
+
+		if _, ok := nodes[node.Name]; ok {
+			return ErrInvalidWorkflowNode
+		}
+
+		// a finished job is mapped back to its node by the job's ID
+		if jobs[node.JobID] {
+			return ErrDuplicateWorkflowJob
+		}
+
+		nodes[node.Name] = node
+		jobs[node.JobID] = true
+	}
+
+	for _, node := range w.Nodes {
+		for _, dependency := range node.DependsOn {
+			if _, ok := nodes[dependency.Node]; !ok {
+				return ErrUnknownWorkflowDependency
+			}
+
+			if !dependency.EffectiveCondition().Valid() {
+				return ErrInvalidWorkflowCondition
+			}
+		}
+	}
+
+	// depth-first search for a dependency that leads back to a node that is being visited
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(w.Nodes))
+
+	var hasCycle func(name string) bool
+	hasCycle = func(name string) bool {
+		switch state[name] {
+		case visiting:
+			return true
+		case visited:
+			return false
+		}
+
+		state[name] = visiting
+		for _, dependency := range nodes[name].DependsOn {
+			if hasCycle(dependency.Node) {
+				return true
+			}
+		}
+		state[name] = visited
+
+		return false
+	}
+
+	for _, node := range w.Nodes {
+		if hasCycle(node.Name) {
+			return ErrWorkflowCycle
+		}
+	}
+
+	return nil
+}
+
+// NewRun returns a new run of the workflow. None of its nodes have been started yet.
+func (w *Workflow) NewRun() *WorkflowRun {
+	run := &WorkflowRun{
+		ID:         uuid.New(),
+		WorkflowID: w.ID,
+		Status:     WorkflowRunStatusRunning,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	for _, node := range w.Nodes {
+		run.Nodes = append(run.Nodes, WorkflowRunNode{
+			Name:      node.Name,
+			JobID:     node.JobID,
+			DependsOn: node.DependsOn,
+			Status:    WorkflowNodeStatusPending,
+		})
+	}
+
+	return run
+}
+
+type WorkflowRunStatus string
+
+const (
+	WorkflowRunStatusRunning   WorkflowRunStatus = "RUNNING"
+	WorkflowRunStatusSucceeded WorkflowRunStatus = "SUCCEEDED" // all nodes succeeded or were skipped
+	WorkflowRunStatusFailed    WorkflowRunStatus = "FAILED"    // at least one node failed
+)
+
+type WorkflowNodeStatus string
+
+const (
+	WorkflowNodeStatusPending   WorkflowNodeStatus = "PENDING"   // waiting for the nodes it depends on
+	WorkflowNodeStatusRunning   WorkflowNodeStatus = "RUNNING"   // the node's job was triggered
+	WorkflowNodeStatusSucceeded WorkflowNodeStatus = "SUCCEEDED" // the node's job run succeeded
+	WorkflowNodeStatusFailed    WorkflowNodeStatus = "FAILED"    // the node's job run failed (after all of its retries)
+	WorkflowNodeStatusSkipped   WorkflowNodeStatus = "SKIPPED"   // the conditions of the node's dependencies were not met
+)
+
+// Finished reports whether the node won't change its status anymore.
+func (ns WorkflowNodeStatus) Finished() bool {
+	return ns == WorkflowNodeStatusSucceeded || ns == WorkflowNodeStatusFailed || ns == WorkflowNodeStatusSkipped
+}
+
+// swagger:model WorkflowRun
+type WorkflowRun struct {
+	ID         uuid.UUID         `json:"id"`
+	WorkflowID uuid.UUID         `json:"workflow_id"`
+	Status     WorkflowRunStatus `json:"status"`
+	Nodes      []WorkflowRunNode `json:"nodes"`
+
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+	FinishedAt null.Time `json:"finished_at" swaggertype:"string"`
+}
+
+// WorkflowRunNode is the state of a node in a run of a workflow. The node is copied from the workflow
+// when the run is started, so changes to the workflow don't affect the runs in progress.
+type WorkflowRunNode struct {
+	Name      string               `json:"name"`
+	JobID     uuid.UUID            `json:"job_id"`
+	DependsOn []WorkflowDependency `json:"depends_on"`
+
+	Status       WorkflowNodeStatus `json:"status"`
+	ErrorMessage null.String        `json:"error_message,omitempty" swaggertype:"string"`
+	StartedAt    null.Time          `json:"started_at" swaggertype:"string"`
+	FinishedAt   null.Time          `json:"finished_at" swaggertype:"string"`
+}
+
+// Node returns the node of the run that runs the given job, or nil if there is none.
+func (r *WorkflowRun) Node(jobID uuid.UUID) *WorkflowRunNode {
+	for i := range r.Nodes {
+		if r.Nodes[i].JobID == jobID {
+			return &r.Nodes[i]
+		}
+	}
+
+	return nil
+}
+
+// Advance starts the pending nodes whose dependencies have finished and met their conditions, and skips the
+// ones whose dependencies have finished without meeting them. It returns the started nodes, whose jobs must
+// be triggered. Once all nodes have finished, the run is finished as well.
+func (r *WorkflowRun) Advance(at time.Time) []*WorkflowRunNode {
+	var started []*WorkflowRunNode
+
+	// skipping a node can settle the nodes that depend on it, so repeat until nothing changes
+	for changed := true; changed; {
+		changed = false
+
+		for i := range r.Nodes {
+			node := &r.Nodes[i]
+			if node.Status != WorkflowNodeStatusPending {
+				continue
+			}
+
+			ready, met := r.dependenciesMet(node)
+			if !ready {
+				continue
+			}
+
+			if met {
+				node.Status = WorkflowNodeStatusRunning
+				node.StartedAt = null.TimeFrom(at)
+				started = append(started, node)
+			} else {
+				node.Status = WorkflowNodeStatusSkipped
+				node.FinishedAt = null.TimeFrom(at)
+			}
+
+			changed = true
+		}
+	}
+
+	r.finish(at)
+	r.UpdatedAt = at
+
+	return started
+}
+
+// CompleteNode records the outcome of the node's job run and advances the run. It returns the nodes
+// that are started as a result. An error message is recorded for failed nodes.
+func (r *WorkflowRun) CompleteNode(node *WorkflowRunNode, errorMessage null.String, at time.Time) []*WorkflowRunNode {
+	node.Status = WorkflowNodeStatusSucceeded
+	if errorMessage.Valid {
+		node.Status = WorkflowNodeStatusFailed
+		node.ErrorMessage = errorMessage
+	}
+	node.FinishedAt = null.TimeFrom(at)
+
+	return r.Advance(at)
+}
+
+// dependenciesMet reports whether all dependencies of the node have finished, and if so, whether their conditions are met.
+func (r *WorkflowRun) dependenciesMet(node *WorkflowRunNode) (ready bool, met bool) {
+	met = true
+
+	for _, dependency := range node.DependsOn {
+		upstream := r.nodeByName(dependency.Node)
+		if upstream == nil {
+			continue
+		}
+
+		if !upstream.Status.Finished() {
+			return false, false
+		}
+
+		switch dependency.EffectiveCondition() {
+		case WorkflowConditionSuccess:
+			met = met && upstream.Status == WorkflowNodeStatusSucceeded
+		case WorkflowConditionFailure:
+			met = met && upstream.Status == WorkflowNodeStatusFailed
+		}
+	}
+
+	return true, met
+}
+
+func (r *WorkflowRun) nodeByName(name string) *WorkflowRunNode {
+	for i := range r.Nodes {
+		if r.Nodes[i].Name == name {
+			return &r.Nodes[i]
+		}
+	}
+
+	return nil
+}
+
+// finish finishes the run once all of its nodes have finished.
+func (r *WorkflowRun) finish(at time.Time) {
+	if r.Status != WorkflowRunStatusRunning {
+		return
+	}
+
+	status := WorkflowRunStatusSucceeded
+	for _, node := range r.Nodes {
+		if !node.Status.Finished() {
+			return
+		}
+
+		if node.Status == WorkflowNodeStatusFailed {
+			status = WorkflowRunStatusFailed
+		}
+	}
+
+	r.Status = status
+	r.FinishedAt = null.TimeFrom(at)
+}
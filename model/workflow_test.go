@@ -0,0 +1,115 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/guregu/null.v4"
+)
+
+func TestWorkflowValidate(t *testing.T) {
+	jobA, jobB, jobC := uuid.New(), uuid.New(), uuid.New()
+
+	dependsOn := func(nodes ...string) []WorkflowDependency {
+		var dependencies []WorkflowDependency
+		for _, node := range nodes {
+			dependencies = append(dependencies, WorkflowDependency{Node: node})
+		}
+		return dependencies
+	}
+
+	tests := []struct {
+		name  string
+		nodes []WorkflowNode
+		want  error
+	}{
+		{
+			name: "valid workflow",
+			nodes: []WorkflowNode{
+				{Name: "a", JobID: jobA},
+				{Name: "b", JobID: jobB, DependsOn: dependsOn("a")},
+				{Name: "c", JobID: jobC, DependsOn: []WorkflowDependency{{Node: "a", Condition: WorkflowConditionAlways}, {Node: "b", Condition: WorkflowConditionFailure}}},
+			},
+		},
+		{
+			name: "no nodes",
+			want: ErrEmptyWorkflowNodes,
+		},
+		{
+			name:  "duplicate node name",
+			nodes: []WorkflowNode{{Name: "a", JobID: jobA}, {Name: "a", JobID: jobB}},
+			want:  ErrInvalidWorkflowNode,
+		},
+		{
+			name:  "duplicate job",
+			nodes: []WorkflowNode{{Name: "a", JobID: jobA}, {Name: "b", JobID: jobA}},
+			want:  ErrDuplicateWorkflowJob,
+		},
+		{
+			name:  "unknown dependency",
+			nodes: []WorkflowNode{{Name: "a", JobID: jobA, DependsOn: dependsOn("z")}},
+			want:  ErrUnknownWorkflowDependency,
+		},
+		{
+			name:  "invalid condition",
+			nodes: []WorkflowNode{{Name: "a", JobID: jobA}, {Name: "b", JobID: jobB, DependsOn: []WorkflowDependency{{Node: "a", Condition: "maybe"}}}},
+			want:  ErrInvalidWorkflowCondition,
+		},
+		{
+			name: "cycle",
+			nodes: []WorkflowNode{
+				{Name: "a", JobID: jobA, DependsOn: dependsOn("c")},
+				{Name: "b", JobID: jobB, DependsOn: dependsOn("a")},
+				{Name: "c", JobID: jobC, DependsOn: dependsOn("b")},
+			},
+			want: ErrWorkflowCycle,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			workflow := (&WorkflowCreate{Name: "billing close", Nodes: tc.nodes}).ToWorkflow()
+			assert.Equal(t, tc.want, workflow.Validate())
+		})
+	}
+}
+
+func TestWorkflowRunAdvance(t *testing.T) {
+	at := time.Date(2023, 6, 1, 10, 0, 0, 0, time.UTC)
+	export, invoices, publish, alert, cleanup := uuid.New(), uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	workflow := &Workflow{ID: uuid.New(), Name: "billing close", Nodes: []WorkflowNode{
+		{Name: "export", JobID: export},
+		{Name: "invoices", JobID: invoices, DependsOn: []WorkflowDependency{{Node: "export"}}},
+		{Name: "publish", JobID: publish, DependsOn: []WorkflowDependency{{Node: "invoices"}}},
+		{Name: "alert", JobID: alert, DependsOn: []WorkflowDependency{{Node: "invoices", Condition: WorkflowConditionFailure}}},
+		{Name: "cleanup", JobID: cleanup, DependsOn: []WorkflowDependency{{Node: "publish", Condition: WorkflowConditionAlways}}},
+	}}
+
+	nodeNames := func(nodes []*WorkflowRunNode) []string {
+		var names []string
+		for _, node := range nodes {
+			names = append(names, node.Name)
+		}
+		return names
+	}
+
+	run := workflow.NewRun()
+	assert.Equal(t, []string{"export"}, nodeNames(run.Advance(at)))
+
+	// a node's outcome decides which of the nodes that depend on it run
+	assert.Equal(t, []string{"invoices"}, nodeNames(run.CompleteNode(run.Node(export), null.String{}, at)))
+	assert.Equal(t, []string{"alert", "cleanup"}, nodeNames(run.CompleteNode(run.Node(invoices), null.StringFrom("failed"), at)))
+
+	assert.Equal(t, WorkflowNodeStatusSkipped, run.Node(publish).Status)
+	assert.Equal(t, WorkflowRunStatusRunning, run.Status)
+
+	// the run finishes once all of its nodes have finished
+	assert.Nil(t, run.CompleteNode(run.Node(alert), null.String{}, at))
+	assert.Nil(t, run.CompleteNode(run.Node(cleanup), null.String{}, at.Add(time.Minute)))
+
+	assert.Equal(t, WorkflowRunStatusFailed, run.Status)
+	assert.Equal(t, null.TimeFrom(at.Add(time.Minute)), run.FinishedAt)
+}
@@ -17,9 +17,14 @@ type mockJobService struct {
 	Jobs   []*model.Job
 	GetErr error
 	FinErr error
+
+	// LostLocks are the jobs whose lock can't be renewed because another instance took it over
+	LostLocks   map[uuid.UUID]bool
+	RenewCalls  int
+	FinishCalls int
 }
 
-func (m *mockJobService) GetJobsToRun(_ context.Context, _ time.Time, _ time.Time, _ string, _ uint) ([]*model.Job, error) {
+func (m *mockJobService) GetJobsToRun(_ context.Context, _ time.Time, _ time.Time, _ string, _ []string, _ uint) ([]*model.Job, error) {
 	m.Lock()
 	defer m.Unlock()
 	if m.GetErr != nil {
@@ -35,6 +40,7 @@ func (m *mockJobService) GetJobsToRun(_ context.Context, _ time.Time, _ time.Tim
 func (m *mockJobService) FinishJobExecution(ctx context.Context, job *model.Job, _, _ time.Time, _ error) error {
 	m.Lock()
 	defer m.Unlock()
+	m.FinishCalls++
 	if m.FinErr != nil {
 		return m.FinErr
 	}
@@ -47,6 +53,21 @@ func (m *mockJobService) FinishJobExecution(ctx context.Context, job *model.Job,
 	return nil
 }
 
+func (m *mockJobService) RenewJobLocks(_ context.Context, locks map[uuid.UUID]int64, _ string, _ time.Time) ([]uuid.UUID, error) {
+	m.Lock()
+	defer m.Unlock()
+	m.RenewCalls++
+
+	renewed := make([]uuid.UUID, 0, len(locks))
+	for id := range locks {
+		if !m.LostLocks[id] {
+			renewed = append(renewed, id)
+		}
+	}
+
+	return renewed, nil
+}
+
 func createMockJobService(getErr, finErr error) *mockJobService {
 	return &mockJobService{
 		Jobs:   []*model.Job{{ID: uuid.MustParse("0053c6a4-ba8b-404e-8e3c-e3875800ed40")}, {ID: uuid.MustParse("0053c6a4-ba8b-404e-8e3c-e3275800ed40")}, {ID: uuid.MustParse("0053c6a4-ba8b-404e-8e3c-e3875800ed40")}},
@@ -63,15 +84,43 @@ func (m *mockJobExecutor) Execute(_ context.Context, _ *model.Job) error {
 	return m.err
 }
 
+// mockBlockingExecutor blocks until the execution context is done
+type mockBlockingExecutor struct{}
+
+func (m *mockBlockingExecutor) Execute(ctx context.Context, _ *model.Job) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// mockSlowExecutor succeeds after the delay, unless the execution context is done before that
+type mockSlowExecutor struct {
+	delay time.Duration
+}
+
+func (m *mockSlowExecutor) Execute(ctx context.Context, _ *model.Job) error {
+	select {
+	case <-time.After(m.delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 type mockExecutorFactory struct {
 	executeErr error
 	factoryErr error
+
+	// executor overrides the default mockJobExecutor
+	executor model.Executor
 }
 
 func (m *mockExecutorFactory) NewExecutor(_ *model.Job, _ ...executor.Option) (model.Executor, error) {
 	if m.factoryErr != nil {
 		return nil, m.factoryErr
 	}
+	if m.executor != nil {
+		return m.executor, nil
+	}
 	return &mockJobExecutor{err: m.executeErr}, nil
 }
 
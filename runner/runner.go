@@ -2,7 +2,10 @@ package runner
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/GLCharge/otelzap"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"sync"
 	"time"
@@ -21,6 +24,9 @@ type Runner struct {
 	// Add an instance ID to identify the runner
 	instanceId string
 
+	// the pools the runner claims jobs from, besides the jobs that are not routed to a pool
+	pools []string
+
 	// Add a context and cancel function to stop the runner
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -42,10 +48,24 @@ type Runner struct {
 
 	// job lock duration
 	jobLockDuration time.Duration
+
+	// how often the locks of in-flight jobs are renewed (disabled when zero)
+	lockRenewInterval time.Duration
+
+	// the in-flight job executions that hold the lock of their job, used to renew the locks and to abort
+	// an execution when its lock is lost or when it is replaced by a new run of the job
+	inFlight   map[uuid.UUID]*inFlightExecution
+	inFlightMu sync.Mutex
+}
+
+type inFlightExecution struct {
+	job    *model.Job
+	cancel context.CancelCauseFunc
 }
 
 type JobService interface {
-	GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error)
+	GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, pools []string, limit uint) ([]*model.Job, error)
+	RenewJobLocks(ctx context.Context, locks map[uuid.UUID]int64, instanceID string, lockedUntil time.Time) ([]uuid.UUID, error)
 	FinishJobExecution(ctx context.Context, job *model.Job, startTime, stopTime time.Time, err error) error
 }
 
@@ -55,9 +75,15 @@ type Config struct {
 	Log             *otelzap.Logger
 	InstanceId      string
 
+	// Pools the runner runs the jobs of, jobs that are not routed to a pool are run by every runner
+	Pools []string
+
 	Interval          time.Duration
 	MaxConcurrentJobs int
 	JobLockDuration   time.Duration
+
+	// LockRenewInterval defaults to a third of the job lock duration
+	LockRenewInterval time.Duration
 }
 
 func New(cfg Config) *Runner {
@@ -66,6 +92,7 @@ func New(cfg Config) *Runner {
 	s := &Runner{
 		jobService:        cfg.JobService,
 		instanceId:        cfg.InstanceId,
+		pools:             cfg.Pools,
 		log:               cfg.Log,
 		ticker:            time.NewTicker(cfg.Interval),
 		ctx:               ctx,
@@ -74,6 +101,12 @@ func New(cfg Config) *Runner {
 		jobSemaphore:      make(chan struct{}, cfg.MaxConcurrentJobs),
 		maxConcurrentJobs: cfg.MaxConcurrentJobs,
 		jobLockDuration:   cfg.JobLockDuration,
+		lockRenewInterval: cfg.LockRenewInterval,
+		inFlight:          make(map[uuid.UUID]*inFlightExecution),
+	}
+
+	if s.lockRenewInterval == 0 {
+		s.lockRenewInterval = cfg.JobLockDuration / 3
 	}
 
 	s.stopWg.Add(1)
@@ -110,6 +143,27 @@ func (s *Runner) start() {
 			}
 		}
 	}()
+
+	// Renew the locks of in-flight jobs in a separate goroutine,
+	// so that it isn't held up while the runner waits for a free job slot
+	if s.lockRenewInterval > 0 {
+		s.stopWg.Add(1)
+		go func() {
+			defer s.stopWg.Done()
+
+			ticker := time.NewTicker(s.lockRenewInterval)
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-ticker.C:
+					s.renewLocks()
+				case <-s.ctx.Done():
+					return
+				}
+			}
+		}()
+	}
 }
 
 // Stop is a method to stop the runner, with a context
@@ -152,7 +206,7 @@ func (s *Runner) runJobs() {
 	defer cancel()
 
 	// Get the jobs that should be run
-	jobs, err := s.jobService.GetJobsToRun(ctx, now, now.Add(s.jobLockDuration), s.instanceId, uint(s.maxConcurrentJobs))
+	jobs, err := s.jobService.GetJobsToRun(ctx, now, now.Add(s.jobLockDuration), s.instanceId, s.pools, uint(s.maxConcurrentJobs))
 	if err != nil {
 		// Log the error and return
 		s.log.Error("Failed to get jobs to run", zap.Error(err))
@@ -178,26 +232,157 @@ func (s *Runner) executeJob(job *model.Job) {
 
 		s.log.Debug("Executing job", zap.Any("jobID", job.ID))
 
-		// Create a new executor for the job with retry enabled
-		jobExecutor, err := s.executorFactory.NewExecutor(job, executor.WithRetry)
+		// Create a new executor for the job (failed attempts are retried through the job service)
+		jobExecutor, err := s.executorFactory.NewExecutor(job)
 		if err != nil {
 			s.log.Error("Failed to create job executor", zap.Any("jobID", job.ID), zap.Error(err))
 			return
 		}
 
+		// Every attempt is a new execution, its ID is passed to the job's templates and recorded with the execution
+		job.ExecutionID = uuid.New()
+		job.Response = nil
+
 		startTime := time.Now()
 
-		// Execute the job
-		err = job.Execute(s.ctx, jobExecutor)
+		// Execute the job, bounded by the job's timeout (if any) and aborted if its lock is lost
+		// or if the job's concurrency policy replaces it with a new run
+		ctx, cancel := context.WithCancelCause(s.ctx)
+		execution := s.trackInFlight(job, cancel)
+
+		err = s.execute(ctx, job, jobExecutor)
+
+		s.untrackInFlight(execution)
+		cancel(nil)
 
 		stopTime := time.Now()
 
-		// Report the job as finished
+		// Another execution owns the job now, so the outcome of this execution must not be reported
+		switch cause := context.Cause(ctx); {
+		case errors.Is(cause, model.ErrJobLockLost):
+			s.log.Warn("Job execution aborted, the job lock was taken over by another instance", zap.Any("jobID", job.ID))
+			return
+		case errors.Is(cause, model.ErrExecutionReplaced):
+			s.log.Info("Job execution aborted, it was replaced by a new run of the job", zap.Any("jobID", job.ID))
+			return
+		}
+
+		// Report the job as finished (stale outcomes are rejected and logged by the job service)
 		err = s.jobService.FinishJobExecution(s.ctx, job, startTime, stopTime, err)
-		if err != nil {
+		if err != nil && !errors.Is(err, model.ErrStaleFencingToken) {
 			s.log.Error("Failed to report job as finished", zap.Any("jobID", job.ID), zap.Error(err))
 		}
 
 		s.log.Debug("Job finished", zap.Any("jobID", job.ID))
 	}()
 }
+
+// execute runs the job with a context that expires after the job's timeout.
+// An execution that is aborted because of the timeout returns model.ErrExecutionTimedOut.
+func (s *Runner) execute(ctx context.Context, job *model.Job, jobExecutor model.Executor) error {
+	if job.Timeout == nil {
+		return job.Execute(ctx, jobExecutor)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, job.Timeout.Duration())
+	defer cancel()
+
+	err := job.Execute(ctx, jobExecutor)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w after %s: %v", model.ErrExecutionTimedOut, job.Timeout.Duration(), err)
+	}
+
+	return err
+}
+
+// trackInFlight registers the execution of the job. If the job is still executing a previous run,
+// which happens when a new run is due while the previous run is in progress, the job's concurrency
+// policy decides what happens to the previous execution: Allow lets it continue, detached from the
+// job's lock, Replace cancels it.
+func (s *Runner) trackInFlight(job *model.Job, cancel context.CancelCauseFunc) *inFlightExecution {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	if previous, ok := s.inFlight[job.ID]; ok {
+		switch job.EffectiveConcurrencyPolicy() {
+		case model.ConcurrencyPolicyAllow:
+			s.log.Info("Job is still executing a previous run, continuing it alongside the new run", zap.Any("jobID", job.ID))
+		case model.ConcurrencyPolicyReplace:
+			s.log.Info("Job is still executing a previous run, replacing it with the new run", zap.Any("jobID", job.ID))
+			previous.cancel(model.ErrExecutionReplaced)
+		default:
+			// the previous run's lock expired before the job was claimed again
+			previous.cancel(model.ErrJobLockLost)
+		}
+	}
+
+	execution := &inFlightExecution{job: job, cancel: cancel}
+	s.inFlight[job.ID] = execution
+
+	return execution
+}
+
+func (s *Runner) untrackInFlight(execution *inFlightExecution) {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	// a newer execution of the job may have taken over its lock
+	if s.inFlight[execution.job.ID] == execution {
+		delete(s.inFlight, execution.job.ID)
+	}
+}
+
+// renewLocks extends the locks of all in-flight jobs. Executions of jobs whose lock could not be
+// renewed because another instance has taken it over are aborted, unless the job allows concurrent runs.
+func (s *Runner) renewLocks() {
+	s.inFlightMu.Lock()
+	locks := make(map[uuid.UUID]int64, len(s.inFlight))
+	for jobID, execution := range s.inFlight {
+		locks[jobID] = execution.job.FencingToken
+	}
+	s.inFlightMu.Unlock()
+
+	if len(locks) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, time.Second*10)
+	defer cancel()
+
+	renewed, err := s.jobService.RenewJobLocks(ctx, locks, s.instanceId, time.Now().Add(s.jobLockDuration))
+	if err != nil {
+		// The locks are still valid until they expire, so try again on the next tick
+		s.log.Error("Failed to renew job locks", zap.Error(err))
+		return
+	}
+
+	renewedIDs := make(map[uuid.UUID]struct{}, len(renewed))
+	for _, jobID := range renewed {
+		renewedIDs[jobID] = struct{}{}
+	}
+
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	for jobID := range locks {
+		if _, ok := renewedIDs[jobID]; ok {
+			continue
+		}
+
+		// the execution may have finished in the meantime
+		execution, ok := s.inFlight[jobID]
+		if !ok {
+			continue
+		}
+
+		if execution.job.EffectiveConcurrencyPolicy() == model.ConcurrencyPolicyAllow {
+			// the execution continues alongside the run of the other instance, it no longer holds the job's lock
+			s.log.Info("Job lock was taken over by another instance, continuing execution", zap.Any("jobID", jobID))
+			delete(s.inFlight, jobID)
+			continue
+		}
+
+		s.log.Warn("Job lock was taken over by another instance, aborting execution", zap.Any("jobID", jobID))
+		execution.cancel(model.ErrJobLockLost)
+	}
+}
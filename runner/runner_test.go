@@ -6,6 +6,11 @@ import (
 	"sync"
 	"testing"
 	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/GLCharge/otelzap"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 func TestNew(t *testing.T) {
@@ -169,3 +174,121 @@ func TestExecuteJob(t *testing.T) {
 		t.Errorf("Expected all jobs to have been processed, but got %d", len(s.jobService.(*mockJobService).Jobs))
 	}
 }
+
+func TestExecuteTimeout(t *testing.T) {
+
+	s := createRunnerWithMockExecutor(time.Second, 1, nil, nil, nil, nil)
+
+	timeout := model.Duration(time.Millisecond * 50)
+	job := &model.Job{Timeout: &timeout}
+
+	start := time.Now()
+	err := s.execute(s.ctx, job, &mockBlockingExecutor{})
+
+	if !errors.Is(err, model.ErrExecutionTimedOut) {
+		t.Errorf("Expected the execution to time out, but got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the execution to be aborted after the timeout, but it took %s", elapsed)
+	}
+
+	// Stopping the runner cancels executions without a timeout, which is not reported as a timeout
+	job.Timeout = nil
+	s.cancel()
+
+	err = s.execute(s.ctx, job, &mockBlockingExecutor{})
+	if err == nil || errors.Is(err, model.ErrExecutionTimedOut) {
+		t.Errorf("Expected the execution to be cancelled, but got %v", err)
+	}
+}
+
+func createRunnerWithLockRenewal(jobService *mockJobService, jobExecutor model.Executor) *Runner {
+	logger, _ := zap.NewDevelopment()
+
+	return New(Config{
+		JobService:        jobService,
+		ExecutorFactory:   &mockExecutorFactory{executor: jobExecutor},
+		Log:               otelzap.New(logger),
+		InstanceId:        "test",
+		Interval:          time.Millisecond * 20,
+		MaxConcurrentJobs: 1,
+		JobLockDuration:   time.Millisecond * 150,
+		LockRenewInterval: time.Millisecond * 50,
+	})
+}
+
+func TestRenewJobLocks(t *testing.T) {
+
+	jobID := uuid.MustParse("0053c6a4-ba8b-404e-8e3c-e3875800ed40")
+
+	// The lock is renewed while the execution runs longer than the lock duration
+	jobService := &mockJobService{Jobs: []*model.Job{{ID: jobID}}}
+	s := createRunnerWithLockRenewal(jobService, &mockSlowExecutor{delay: time.Millisecond * 300})
+	s.Start()
+
+	time.Sleep(time.Millisecond * 500)
+	s.Stop(context.Background())
+
+	assertJobsProcessed(t, jobService)
+	if jobService.RenewCalls == 0 {
+		t.Error("Expected the job lock to have been renewed")
+	}
+
+	// The execution is aborted and not reported once another instance took over the lock
+	jobService = &mockJobService{Jobs: []*model.Job{{ID: jobID}}, LostLocks: map[uuid.UUID]bool{jobID: true}}
+	s = createRunnerWithLockRenewal(jobService, &mockSlowExecutor{delay: time.Hour})
+	s.Start()
+
+	time.Sleep(time.Millisecond * 200)
+
+	jobService.Lock()
+	renewCalls, finishCalls := jobService.RenewCalls, jobService.FinishCalls
+	jobService.Unlock()
+
+	s.Stop(context.Background())
+
+	if renewCalls == 0 {
+		t.Error("Expected the job lock renewal to have been attempted")
+	}
+	if finishCalls != 0 {
+		t.Errorf("Expected the aborted execution not to be reported, but it was reported %d times", finishCalls)
+	}
+}
+
+func TestTrackInFlightConcurrencyPolicy(t *testing.T) {
+
+	s := createRunnerWithMockExecutor(time.Second, 1, nil, nil, nil, nil)
+	jobID := uuid.MustParse("0053c6a4-ba8b-404e-8e3c-e3875800ed40")
+
+	for _, tc := range []struct {
+		policy    model.ConcurrencyPolicy
+		wantCause error
+	}{
+		{policy: model.ConcurrencyPolicyReplace, wantCause: model.ErrExecutionReplaced},
+		{policy: model.ConcurrencyPolicyForbid, wantCause: model.ErrJobLockLost},
+		{policy: model.ConcurrencyPolicyAllow, wantCause: nil},
+	} {
+		job := &model.Job{ID: jobID, ConcurrencyPolicy: tc.policy}
+
+		previousCtx, previousCancel := context.WithCancelCause(context.Background())
+		previous := s.trackInFlight(job, previousCancel)
+
+		_, cancel := context.WithCancelCause(context.Background())
+		execution := s.trackInFlight(job, cancel)
+
+		if cause := context.Cause(previousCtx); !errors.Is(cause, tc.wantCause) {
+			t.Errorf("%s: expected the previous execution to be cancelled with %v, got %v", tc.policy, tc.wantCause, cause)
+		}
+
+		// the previous execution finishing doesn't untrack the new one
+		s.untrackInFlight(previous)
+		if s.inFlight[jobID] != execution {
+			t.Errorf("%s: expected the new execution to hold the job's lock", tc.policy)
+		}
+
+		s.untrackInFlight(execution)
+		previousCancel(nil)
+		cancel(nil)
+	}
+}
@@ -2,8 +2,10 @@ package job
 
 import (
 	"context"
+	"errors"
 	"github.com/GLCharge/otelzap"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 	"gopkg.in/guregu/null.v4"
 	"time"
 
@@ -90,39 +92,169 @@ func (s *Service) ListJobs(ctx context.Context, limit, offset uint64, tags []str
 	return s.store.ListJobs(ctx, limit, offset, tags)
 }
 
-// GetJobsToRun returns a list of jobs that should be run at the given time.
-func (s *Service) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error) {
+// PauseJob stops the job with the given ID from being scheduled until it is resumed.
+func (s *Service) PauseJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	job, err := s.store.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.pauseJob(ctx, job); err != nil {
+		return nil, err
+	}
 
-	return s.store.GetJobsToRun(ctx, at, lockedUntil, instanceID, limit)
+	return job, nil
 }
 
-func (s *Service) FinishJobExecution(ctx context.Context, job *model.Job, startTime, stopTime time.Time, err error) error {
+// ResumeJob schedules the paused job with the given ID again. The next run of a recurring job is computed
+// from its cron schedule, the runs missed while the job was paused are not made up for.
+func (s *Service) ResumeJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	job, err := s.store.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
 
-	// Update the job execution
-	job.SetNextRunTime()
+	if err := s.resumeJob(ctx, job); err != nil {
+		return nil, err
+	}
 
-	// finish the job in the store (update the next run time and clear lock)
-	err2 := s.store.FinishJob(ctx, job.ID, job.NextRun)
-	if err2 != nil {
-		return err
+	return job, nil
+}
+
+// PauseJobsByTags pauses all jobs that have the given tags and returns them. The jobs are paused at once,
+// either all of them or none.
+func (s *Service) PauseJobsByTags(ctx context.Context, tags []string) ([]model.Job, error) {
+	if len(tags) == 0 {
+		return nil, model.ErrEmptyTags
+	}
+
+	return s.store.PauseJobsByTags(ctx, tags)
+}
+
+// ResumeJobsByTags resumes all jobs that have the given tags and returns them. The jobs are resumed at once,
+// either all of them or none.
+func (s *Service) ResumeJobsByTags(ctx context.Context, tags []string) ([]model.Job, error) {
+	if len(tags) == 0 {
+		return nil, model.ErrEmptyTags
 	}
 
-	jobExecutionStatus := model.JobExecutionStatusSuccessful
-	errorMessage := null.String{}
+	return s.store.ResumeJobsByTags(ctx, tags)
+}
+
+func (s *Service) pauseJob(ctx context.Context, job *model.Job) error {
+	// pausing a paused job is a no-op
+	if job.Status == model.JobStatusStopped {
+		return nil
+	}
+
+	job.Pause()
+
+	return s.store.SetJobStatus(ctx, job.ID, job.Status, job.NextRun)
+}
+
+func (s *Service) resumeJob(ctx context.Context, job *model.Job) error {
+	// resuming a running job is a no-op, so its schedule and pending retry are kept
+	if job.Status == model.JobStatusRunning {
+		return nil
+	}
+
+	job.Resume()
+
+	return s.store.SetJobStatus(ctx, job.ID, job.Status, job.NextRun)
+}
+
+// TriggerJob requests a manual run of the job with the given ID, which a runner picks up like any other due job.
+// The manual run doesn't change the job's schedule.
+func (s *Service) TriggerJob(ctx context.Context, id uuid.UUID) (*model.Job, error) {
+	if err := s.store.TriggerJob(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return s.store.GetJob(ctx, id)
+}
+
+// GetJobsToRun returns a list of jobs that should be run at the given time by a runner in the given pools.
+// Jobs that are not routed to a pool are run by any runner.
+func (s *Service) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, pools []string, limit uint) ([]*model.Job, error) {
+
+	return s.store.GetJobsToRun(ctx, at, lockedUntil, instanceID, pools, limit)
+}
+
+// RenewJobLocks extends the locks the instance holds on the given jobs, each with the fencing token of its
+// claim, and returns the IDs of the jobs that are still locked by the instance with the same claim.
+func (s *Service) RenewJobLocks(ctx context.Context, locks map[uuid.UUID]int64, instanceID string, lockedUntil time.Time) ([]uuid.UUID, error) {
+
+	return s.store.RenewJobLocks(ctx, locks, instanceID, lockedUntil)
+}
+
+// FinishJobExecution records the outcome of an attempt of the job's current run. A failed attempt that the
+// job's retry policy allows to retry is rescheduled, otherwise the run is over and the next run is scheduled.
+//
+// The outcome is only applied while the job's claim is still the one the execution was made with; a stale
+// outcome, reported after another runner claimed the job again, is rejected with model.ErrStaleFencingToken.
+func (s *Service) FinishJobExecution(ctx context.Context, job *model.Job, startTime, stopTime time.Time, err error) error {
+
+	execution := &model.JobExecution{
+		JobID:       job.ID,
+		RunID:       job.RunID,
+		ExecutionID: uuid.NullUUID{UUID: job.ExecutionID, Valid: job.ExecutionID != uuid.Nil},
+		Attempt:     job.Attempt,
+		Trigger:     job.RunTrigger,
+		StartTime:   startTime,
+		EndTime:     stopTime,
+		Status:      model.JobExecutionStatusSuccessful,
+		Response:    job.Response,
+	}
 	if err != nil {
-		jobExecutionStatus = model.JobExecutionStatusFailed
-		errorMessage = null.StringFrom(err.Error())
+		execution.Status = model.JobExecutionStatusFailed
+		if errors.Is(err, model.ErrExecutionTimedOut) {
+			execution.Status = model.JobExecutionStatusTimedOut
+		}
+		execution.ErrorMessage = null.StringFrom(err.Error())
+	}
+
+	// Create the job execution first, the job's lock keeps other runners from claiming it in the meantime
+	if err2 := s.store.CreateJobExecution(ctx, execution, job.FencingToken); err2 != nil {
+		return s.rejectStale(job, err2)
+	}
+
+	if job.ShouldRetry(err) {
+		// release the job until the retry is due, so that any runner can pick it up
+		retryAt := stopTime.Add(job.EffectiveRetryPolicy().Interval(job.Attempt))
+
+		if err2 := s.store.ScheduleJobRetry(ctx, job.ID, job.FencingToken, retryAt); err2 != nil {
+			return s.rejectStale(job, err2)
+		}
+
+		return nil
 	}
 
-	// Create the job execution
-	err2 = s.store.CreateJobExecution(ctx, job.ID, startTime, stopTime, jobExecutionStatus, errorMessage)
-	if err2 != nil {
-		return err2
+	// finish the job in the store (the next run time was already set when the run was started), a run started
+	// by a workflow run lets the workflow run continue with the nodes that depend on the job
+	if err2 := s.store.FinishJob(ctx, job, execution.ErrorMessage, stopTime); err2 != nil {
+		return s.rejectStale(job, err2)
 	}
 
 	return nil
 }
 
+// rejectStale logs the rejection of a stale job outcome and returns err.
+func (s *Service) rejectStale(job *model.Job, err error) error {
+	// the runs of a job that allows concurrent runs are expected to overlap, only the latest run updates the job
+	if errors.Is(err, model.ErrStaleFencingToken) && job.EffectiveConcurrencyPolicy() == model.ConcurrencyPolicyAllow {
+		s.log.Info("Job run was overtaken by a concurrent run of the job",
+			zap.Any("jobID", job.ID), zap.Int64("fencingToken", job.FencingToken), zap.Int("attempt", job.Attempt))
+		return err
+	}
+
+	if errors.Is(err, model.ErrStaleFencingToken) {
+		s.log.Warn("Rejected the outcome of a stale job execution",
+			zap.Any("jobID", job.ID), zap.Int64("fencingToken", job.FencingToken), zap.Int("attempt", job.Attempt))
+	}
+
+	return err
+}
+
 func (s *Service) GetJobExecutions(ctx context.Context, id uuid.UUID, failedOnly bool, limit uint64, offset uint64) ([]*model.JobExecution, error) {
 
 	return s.store.GetJobExecutions(ctx, id, failedOnly, limit, offset)
@@ -2,6 +2,7 @@ package job
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/GLCharge/distributed-scheduler/foundation/database/dbtest"
 	"github.com/GLCharge/distributed-scheduler/foundation/docker"
@@ -33,6 +34,17 @@ func TestMain(m *testing.M) {
 func Test_Job(t *testing.T) {
 	t.Run("crud", crud)
 	t.Run("job_execution", jobExecution)
+	t.Run("job_retry", jobRetry)
+	t.Run("job_fencing", jobFencing)
+	t.Run("job_fencing_allow", jobFencingAllow)
+	t.Run("job_pause", jobPause)
+	t.Run("job_trigger", jobTrigger)
+	t.Run("job_misfire", jobMisfire)
+	t.Run("job_concurrency", jobConcurrency)
+	t.Run("job_concurrency_retry", jobConcurrencyRetry)
+	t.Run("job_priority", jobPriority)
+	t.Run("job_pool", jobPool)
+	t.Run("job_template", jobTemplate)
 }
 
 func crud(t *testing.T) {
@@ -60,6 +72,7 @@ func crud(t *testing.T) {
 		Type:         model.JobTypeHTTP,
 		CronSchedule: null.StringFrom("@every 1m"),
 		HTTPJob:      &model.HTTPJob{URL: "https://google.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		RetryPolicy:  &model.RetryPolicy{MaxAttempts: 5, RetryOn: []model.FailureClass{model.FailureClassHTTP5xx}},
 	})
 
 	if err != nil {
@@ -120,7 +133,7 @@ func crud(t *testing.T) {
 	// Get jobs
 	// -------------------------------------------------------------------------
 
-	jobs, err := jobService.ListJobs(ctx, 10, 0)
+	jobs, err := jobService.ListJobs(ctx, 10, 0, nil)
 	if err != nil {
 		t.Fatalf("Should be able to list jobs: %s", err)
 	}
@@ -132,7 +145,7 @@ func crud(t *testing.T) {
 	// Get jobs with limit
 	// -------------------------------------------------------------------------
 
-	jobs, err = jobService.ListJobs(ctx, 1, 0)
+	jobs, err = jobService.ListJobs(ctx, 1, 0, nil)
 	if err != nil {
 		t.Fatalf("Should be able to list jobs: %s", err)
 	}
@@ -198,7 +211,7 @@ func jobExecution(t *testing.T) {
 	// Get jobs to run
 	// -------------------------------------------------------------------------
 
-	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(5*time.Second), "instance1", 10)
+	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(5*time.Second), "instance1", nil, 10)
 	if err != nil {
 		t.Fatalf("Should be able to get jobs to run: %s", err)
 	}
@@ -214,7 +227,7 @@ func jobExecution(t *testing.T) {
 	// Get jobs to run
 	// -------------------------------------------------------------------------
 
-	jobs, err = jobService.GetJobsToRun(ctx, now.Add(4*time.Second), now.Add(6*time.Second), "instance1", 10)
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(4*time.Second), now.Add(6*time.Second), "instance1", nil, 10)
 	if err != nil {
 		t.Fatalf("Should be able to get jobs to run: %s", err)
 	}
@@ -227,7 +240,7 @@ func jobExecution(t *testing.T) {
 	// Get jobs to run
 	// -------------------------------------------------------------------------
 
-	jobs, err = jobService.GetJobsToRun(ctx, now.Add(6*time.Second), now.Add(8*time.Second), "instance2", 10)
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(6*time.Second), now.Add(8*time.Second), "instance2", nil, 10)
 	if err != nil {
 		t.Fatalf("Should be able to get jobs to run: %s", err)
 	}
@@ -249,7 +262,7 @@ func jobExecution(t *testing.T) {
 		t.Fatalf("Should be able to finish job execution: %s", err)
 	}
 
-	jobs, err = jobService.GetJobsToRun(ctx, now.Add(10*time.Second), now.Add(12*time.Second), "instance2", 10)
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(10*time.Second), now.Add(12*time.Second), "instance2", nil, 10)
 	if err != nil {
 		t.Fatalf("Should be able to get jobs to run: %s", err)
 	}
@@ -284,3 +297,1088 @@ func jobExecution(t *testing.T) {
 		t.Fatalf("Should get back 0 failed job executions: %d", len(jobExecutions))
 	}
 }
+
+func jobRetry(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// Create job
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:        model.JobTypeHTTP,
+		ExecuteAt:   null.TimeFrom(now.Add(1 * time.Second)),
+		HTTPJob:     &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		RetryPolicy: &model.RetryPolicy{MaxAttempts: 2, InitialInterval: model.Duration(10 * time.Second)},
+	})
+
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// First attempt fails
+	// -------------------------------------------------------------------------
+
+	jobs, err := jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(5*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].Attempt != 1 {
+		t.Fatalf("Should get back the first attempt of the job: %v", jobs)
+	}
+
+	runID := jobs[0].RunID
+
+	err = jobService.FinishJobExecution(ctx, jobs[0], now.Add(2*time.Second), now.Add(3*time.Second), &model.HTTPStatusError{StatusCode: 500})
+	if err != nil {
+		t.Fatalf("Should be able to finish job execution: %s", err)
+	}
+
+	// Retry is not due yet, even though the job is no longer locked
+	// -------------------------------------------------------------------------
+
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(5*time.Second), now.Add(8*time.Second), "instance2", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 0 {
+		t.Fatalf("Should get back 0 jobs: %d", len(jobs))
+	}
+
+	// Second attempt is picked up by another runner and fails as well
+	// -------------------------------------------------------------------------
+
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(14*time.Second), now.Add(17*time.Second), "instance2", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].Attempt != 2 || jobs[0].RunID != runID {
+		t.Fatalf("Should get back the second attempt of the same run: %v", jobs)
+	}
+
+	err = jobService.FinishJobExecution(ctx, jobs[0], now.Add(14*time.Second), now.Add(15*time.Second), &model.HTTPStatusError{StatusCode: 500})
+	if err != nil {
+		t.Fatalf("Should be able to finish job execution: %s", err)
+	}
+
+	// Retries are exhausted, so the job is not run again
+	// -------------------------------------------------------------------------
+
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(60*time.Second), now.Add(65*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 0 {
+		t.Fatalf("Should get back 0 jobs: %d", len(jobs))
+	}
+
+	// Every attempt has its own execution linked to the run
+	// -------------------------------------------------------------------------
+
+	jobExecutions, err := jobService.GetJobExecutions(ctx, job.ID, true, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	if len(jobExecutions) != 2 {
+		t.Fatalf("Should get back 2 failed job executions: %d", len(jobExecutions))
+	}
+
+	for i, execution := range jobExecutions {
+		if execution.RunID != runID || execution.Attempt != 2-i {
+			t.Fatalf("Should get back the attempts of the run: %+v", execution)
+		}
+	}
+}
+
+func jobFencing(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create job
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("*/5 * * * *"),
+		HTTPJob:      &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// The lock of the first runner expires and another runner claims the job
+	// -------------------------------------------------------------------------
+
+	at := job.NextRun.Time.Add(time.Second)
+
+	staleJobs, err := jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(staleJobs) != 1 {
+		t.Fatalf("Should get back 1 job: %d", len(staleJobs))
+	}
+
+	jobs, err := jobService.GetJobsToRun(ctx, at.Add(10*time.Second), at.Add(15*time.Second), "instance2", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].FencingToken <= staleJobs[0].FencingToken {
+		t.Fatalf("Should get back the job with a newer fencing token: %v", jobs)
+	}
+
+	// The first runner's outcome is rejected
+	// -------------------------------------------------------------------------
+
+	err = jobService.FinishJobExecution(ctx, staleJobs[0], at, at.Add(20*time.Second), nil)
+	if !errors.Is(err, model.ErrStaleFencingToken) {
+		t.Fatalf("Should reject the stale outcome: %v", err)
+	}
+
+	jobExecutions, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	if len(jobExecutions) != 0 {
+		t.Fatalf("Should get back 0 job executions: %d", len(jobExecutions))
+	}
+
+	// The second runner's outcome is applied
+	// -------------------------------------------------------------------------
+
+	err = jobService.FinishJobExecution(ctx, jobs[0], at.Add(10*time.Second), at.Add(11*time.Second), nil)
+	if err != nil {
+		t.Fatalf("Should be able to finish job execution: %s", err)
+	}
+
+	jobExecutions, err = jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	if len(jobExecutions) != 1 || jobExecutions[0].Status != model.JobExecutionStatusSuccessful {
+		t.Fatalf("Should get back 1 successful job execution: %v", jobExecutions)
+	}
+
+	// A superseded claim can't renew the lock, even if the same runner claimed the job again
+	// -------------------------------------------------------------------------
+
+	at = job.NextRun.Time.Add(5*time.Minute + time.Second)
+
+	staleJobs, err = jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(staleJobs) != 1 {
+		t.Fatalf("Should get back 1 job: %d", len(staleJobs))
+	}
+
+	jobs, err = jobService.GetJobsToRun(ctx, at.Add(10*time.Second), at.Add(15*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].FencingToken <= staleJobs[0].FencingToken {
+		t.Fatalf("Should get back the job with a newer fencing token: %v", jobs)
+	}
+
+	renewed, err := jobService.RenewJobLocks(ctx, map[uuid.UUID]int64{job.ID: staleJobs[0].FencingToken}, "instance1", at.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Should be able to renew job locks: %s", err)
+	}
+
+	if len(renewed) != 0 {
+		t.Fatalf("Should not renew the lock of the superseded claim: %v", renewed)
+	}
+
+	renewed, err = jobService.RenewJobLocks(ctx, map[uuid.UUID]int64{job.ID: jobs[0].FencingToken}, "instance1", at.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("Should be able to renew job locks: %s", err)
+	}
+
+	if len(renewed) != 1 || renewed[0] != job.ID {
+		t.Fatalf("Should renew the lock of the current claim: %v", renewed)
+	}
+}
+
+func jobFencingAllow(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create job
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:              model.JobTypeHTTP,
+		CronSchedule:      null.StringFrom("*/5 * * * *"),
+		HTTPJob:           &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		ConcurrencyPolicy: model.ConcurrencyPolicyAllow,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// The lock of the first runner expires and another runner makes the next attempt of the run
+	// -------------------------------------------------------------------------
+
+	at := job.NextRun.Time.Add(time.Second)
+
+	staleJobs, err := jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(staleJobs) != 1 {
+		t.Fatalf("Should get back 1 job: %d", len(staleJobs))
+	}
+
+	jobs, err := jobService.GetJobsToRun(ctx, at.Add(10*time.Second), at.Add(time.Hour), "instance2", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].RunID != staleJobs[0].RunID || jobs[0].FencingToken <= staleJobs[0].FencingToken {
+		t.Fatalf("Should get back the next attempt of the same run: %v", jobs)
+	}
+
+	// The first runner's execution of the same run is rejected
+	// -------------------------------------------------------------------------
+
+	err = jobService.FinishJobExecution(ctx, staleJobs[0], at, at.Add(20*time.Second), nil)
+	if !errors.Is(err, model.ErrStaleFencingToken) {
+		t.Fatalf("Should reject the stale outcome: %v", err)
+	}
+
+	jobExecutions, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	if len(jobExecutions) != 0 {
+		t.Fatalf("Should get back 0 job executions: %d", len(jobExecutions))
+	}
+
+	// A new run starts alongside the run in progress, which still records its execution
+	// -------------------------------------------------------------------------
+
+	at = at.Add(5 * time.Minute)
+
+	newJobs, err := jobService.GetJobsToRun(ctx, at, at.Add(time.Hour), "instance3", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(newJobs) != 1 || newJobs[0].RunID == jobs[0].RunID {
+		t.Fatalf("Should get back a new run of the job: %v", newJobs)
+	}
+
+	err = jobService.FinishJobExecution(ctx, jobs[0], at, at.Add(time.Second), nil)
+	if !errors.Is(err, model.ErrStaleFencingToken) {
+		t.Fatalf("Should not let the overtaken run update the job: %v", err)
+	}
+
+	jobExecutions, err = jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	if len(jobExecutions) != 1 || jobExecutions[0].RunID != jobs[0].RunID {
+		t.Fatalf("Should get back the execution of the overtaken run: %v", jobExecutions)
+	}
+}
+
+func jobPause(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := postgres.New(test.DB, test.Log)
+	jobService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	var jobIDs []uuid.UUID
+	for _, tags := range [][]string{{"billing", "daily"}, {"billing"}, {"reports"}} {
+		job, err := jobService.CreateJob(ctx, &model.JobCreate{
+			Type:         model.JobTypeHTTP,
+			CronSchedule: null.StringFrom("*/5 * * * *"),
+			HTTPJob:      &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+			Tags:         tags,
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create a job: %s", err)
+		}
+
+		jobIDs = append(jobIDs, job.ID)
+	}
+
+	// Pause a single job
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.PauseJob(ctx, jobIDs[2])
+	if err != nil {
+		t.Fatalf("Should be able to pause the job: %s", err)
+	}
+
+	if job.Status != model.JobStatusStopped {
+		t.Fatalf("Should get back the paused job: %s", job.Status)
+	}
+
+	// Pause jobs by tags
+	// -------------------------------------------------------------------------
+
+	if _, err := jobService.PauseJobsByTags(ctx, nil); !errors.Is(err, model.ErrEmptyTags) {
+		t.Fatalf("Should not be able to pause jobs without tags: %v", err)
+	}
+
+	stale, err := jobService.GetJob(ctx, jobIDs[1])
+	if err != nil {
+		t.Fatalf("Should be able to get the job: %s", err)
+	}
+
+	jobs, err := jobService.PauseJobsByTags(ctx, []string{"billing"})
+	if err != nil {
+		t.Fatalf("Should be able to pause jobs by tags: %s", err)
+	}
+
+	if len(jobs) != 2 {
+		t.Fatalf("Should get back 2 paused jobs: %d", len(jobs))
+	}
+
+	for _, job := range jobs {
+		if job.Status != model.JobStatusStopped {
+			t.Fatalf("Should get back the paused jobs: %+v", job)
+		}
+	}
+
+	// An update made from a read before the pause keeps the job paused
+	// -------------------------------------------------------------------------
+
+	stale.Priority = 5
+	if err := store.UpdateJob(ctx, stale); err != nil {
+		t.Fatalf("Should be able to update the job: %s", err)
+	}
+
+	job, err = jobService.GetJob(ctx, jobIDs[1])
+	if err != nil {
+		t.Fatalf("Should be able to get the job: %s", err)
+	}
+
+	if job.Status != model.JobStatusStopped || job.Priority != 5 {
+		t.Fatalf("Should get back the updated job still paused: %+v", job)
+	}
+
+	// Paused jobs are not run
+	// -------------------------------------------------------------------------
+
+	at := time.Now().Add(10 * time.Minute)
+
+	toRun, err := jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(toRun) != 0 {
+		t.Fatalf("Should get back 0 jobs: %d", len(toRun))
+	}
+
+	// Resumed jobs skip the runs they missed
+	// -------------------------------------------------------------------------
+
+	jobs, err = jobService.ResumeJobsByTags(ctx, []string{"daily"})
+	if err != nil {
+		t.Fatalf("Should be able to resume jobs by tags: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].ID != jobIDs[0] || jobs[0].Status != model.JobStatusRunning || !jobs[0].NextRun.Time.After(time.Now()) {
+		t.Fatalf("Should get back the resumed job scheduled in the future: %v", jobs)
+	}
+
+	job, err = jobService.ResumeJob(ctx, jobIDs[2])
+	if err != nil {
+		t.Fatalf("Should be able to resume the job: %s", err)
+	}
+
+	job, err = jobService.GetJob(ctx, jobIDs[2])
+	if err != nil {
+		t.Fatalf("Should be able to get the job: %s", err)
+	}
+
+	if job.Status != model.JobStatusRunning || !job.NextRun.Time.After(time.Now()) {
+		t.Fatalf("Should get back the resumed job scheduled in the future: %+v", job)
+	}
+
+	job, err = jobService.GetJob(ctx, jobIDs[1])
+	if err != nil {
+		t.Fatalf("Should be able to get the job: %s", err)
+	}
+
+	if job.Status != model.JobStatusStopped {
+		t.Fatalf("Should not resume jobs without the tags: %s", job.Status)
+	}
+}
+
+func jobTrigger(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create job
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("0 0 1 1 *"),
+		HTTPJob:      &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// Trigger the job
+	// -------------------------------------------------------------------------
+
+	if _, err := jobService.TriggerJob(ctx, uuid.New()); !errors.Is(err, model.ErrJobNotFound) {
+		t.Fatalf("Should not be able to trigger a job that doesn't exist: %v", err)
+	}
+
+	triggered, err := jobService.TriggerJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Should be able to trigger the job: %s", err)
+	}
+
+	if !triggered.TriggeredAt.Valid {
+		t.Fatalf("Should get back the triggered job: %+v", triggered)
+	}
+
+	// The manual run is picked up by a runner
+	// -------------------------------------------------------------------------
+
+	now := time.Now()
+
+	jobs, err := jobService.GetJobsToRun(ctx, now.Add(time.Second), now.Add(5*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].RunTrigger != model.RunTriggerManual {
+		t.Fatalf("Should get back the manual run of the job: %v", jobs)
+	}
+
+	err = jobService.FinishJobExecution(ctx, jobs[0], now.Add(time.Second), now.Add(2*time.Second), nil)
+	if err != nil {
+		t.Fatalf("Should be able to finish job execution: %s", err)
+	}
+
+	// The job's schedule is untouched and the execution is marked as manual
+	// -------------------------------------------------------------------------
+
+	got, err := jobService.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get the job: %s", err)
+	}
+
+	if !got.NextRun.Time.Equal(job.NextRun.Time) || got.TriggeredAt.Valid {
+		t.Fatalf("Should keep the next run of the job: %v, got %v", job.NextRun, got.NextRun)
+	}
+
+	jobExecutions, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	if len(jobExecutions) != 1 || jobExecutions[0].Trigger != model.RunTriggerManual {
+		t.Fatalf("Should get back 1 manual job execution: %v", jobExecutions)
+	}
+
+	// The job is not run again until it is due
+	// -------------------------------------------------------------------------
+
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(3*time.Second), now.Add(8*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 0 {
+		t.Fatalf("Should get back 0 jobs: %d", len(jobs))
+	}
+}
+
+func jobMisfire(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create job
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:          model.JobTypeHTTP,
+		CronSchedule:  null.StringFrom("0 * * * *"),
+		HTTPJob:       &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		MisfirePolicy: model.MisfirePolicySkipToNext,
+	})
+
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// The runs missed while no runner was up are skipped
+	// -------------------------------------------------------------------------
+
+	at := job.NextRun.Time.Add(2*time.Hour + 30*time.Minute)
+
+	jobs, err := jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 0 {
+		t.Fatalf("Should get back 0 jobs: %d", len(jobs))
+	}
+
+	got, err := jobService.GetJob(ctx, job.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get the job: %s", err)
+	}
+
+	if !got.NextRun.Time.Equal(job.NextRun.Time.Add(3 * time.Hour)) {
+		t.Fatalf("Should move the next run past the missed runs: %v", got.NextRun)
+	}
+
+	// The missed runs are recorded
+	// -------------------------------------------------------------------------
+
+	jobExecutions, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	if len(jobExecutions) != 3 {
+		t.Fatalf("Should get back 3 job executions: %d", len(jobExecutions))
+	}
+
+	for _, execution := range jobExecutions {
+		if execution.Status != model.JobExecutionStatusMissed {
+			t.Fatalf("Should get back missed job executions: %+v", execution)
+		}
+	}
+}
+
+func jobConcurrency(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	forbid, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:              model.JobTypeHTTP,
+		CronSchedule:      null.StringFrom("*/5 * * * *"),
+		HTTPJob:           &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		ConcurrencyPolicy: model.ConcurrencyPolicyForbid,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	replace, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:              model.JobTypeHTTP,
+		CronSchedule:      null.StringFrom("*/5 * * * *"),
+		HTTPJob:           &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		ConcurrencyPolicy: model.ConcurrencyPolicyReplace,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// Both jobs start a run that takes longer than their schedule
+	// -------------------------------------------------------------------------
+
+	at := forbid.NextRun.Time.Add(time.Second)
+
+	running, err := jobService.GetJobsToRun(ctx, at, at.Add(time.Hour), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(running) != 2 {
+		t.Fatalf("Should get back 2 jobs: %d", len(running))
+	}
+
+	// The next run is due while the previous run is still in progress
+	// -------------------------------------------------------------------------
+
+	at = at.Add(5 * time.Minute)
+
+	jobs, err := jobService.GetJobsToRun(ctx, at, at.Add(time.Hour), "instance2", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].ID != replace.ID {
+		t.Fatalf("Should get back the new run of the replaced job: %v", jobs)
+	}
+
+	for _, job := range running {
+		if job.ID == replace.ID && (jobs[0].RunID == job.RunID || jobs[0].FencingToken <= job.FencingToken) {
+			t.Fatalf("Should start a new run of the replaced job: %+v", jobs[0])
+		}
+	}
+
+	// The run of the job that forbids concurrent runs is skipped
+	// -------------------------------------------------------------------------
+
+	got, err := jobService.GetJob(ctx, forbid.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get the job: %s", err)
+	}
+
+	if !got.NextRun.Time.After(at) {
+		t.Fatalf("Should move the next run past the skipped run: %v", got.NextRun)
+	}
+
+	jobExecutions, err := jobService.GetJobExecutions(ctx, forbid.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	if len(jobExecutions) != 1 || jobExecutions[0].Status != model.JobExecutionStatusSkipped {
+		t.Fatalf("Should get back 1 skipped job execution: %v", jobExecutions)
+	}
+}
+
+func jobConcurrencyRetry(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create job
+	// -------------------------------------------------------------------------
+
+	job, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:              model.JobTypeHTTP,
+		CronSchedule:      null.StringFrom("*/5 * * * *"),
+		HTTPJob:           &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		RetryPolicy:       &model.RetryPolicy{MaxAttempts: 3, InitialInterval: model.Duration(10 * time.Second)},
+		ConcurrencyPolicy: model.ConcurrencyPolicyForbid,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// The first attempt fails
+	// -------------------------------------------------------------------------
+
+	at := job.NextRun.Time.Add(time.Second)
+
+	jobs, err := jobService.GetJobsToRun(ctx, at, at.Add(time.Minute), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].Attempt != 1 {
+		t.Fatalf("Should get back the first attempt of the job: %v", jobs)
+	}
+
+	err = jobService.FinishJobExecution(ctx, jobs[0], at, at.Add(time.Second), &model.HTTPStatusError{StatusCode: 500})
+	if err != nil {
+		t.Fatalf("Should be able to finish job execution: %s", err)
+	}
+
+	// The retry attempt takes longer than the job's schedule
+	// -------------------------------------------------------------------------
+
+	at = at.Add(time.Minute)
+
+	retries, err := jobService.GetJobsToRun(ctx, at, at.Add(time.Hour), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(retries) != 1 || retries[0].Attempt != 2 || retries[0].RunID != jobs[0].RunID {
+		t.Fatalf("Should get back the second attempt of the same run: %v", retries)
+	}
+
+	// The next run is due while the retry attempt is still in progress
+	// -------------------------------------------------------------------------
+
+	at = at.Add(5 * time.Minute)
+
+	jobs, err = jobService.GetJobsToRun(ctx, at, at.Add(time.Hour), "instance2", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 0 {
+		t.Fatalf("Should not claim the job while its retry attempt is in progress: %v", jobs)
+	}
+
+	jobExecutions, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	skipped := lo.Filter(jobExecutions, func(e *model.JobExecution, _ int) bool { return e.Status == model.JobExecutionStatusSkipped })
+	if len(skipped) != 1 {
+		t.Fatalf("Should skip the run by the concurrency policy: %v", jobExecutions)
+	}
+
+	// The retry attempt still holds the job's lock
+	// -------------------------------------------------------------------------
+
+	err = jobService.FinishJobExecution(ctx, retries[0], at, at.Add(time.Second), nil)
+	if err != nil {
+		t.Fatalf("Should be able to finish the retry attempt: %s", err)
+	}
+}
+
+func jobPriority(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	createJob := func(executeAt time.Time, priority int) *model.Job {
+		job, err := jobService.CreateJob(ctx, &model.JobCreate{
+			Type:      model.JobTypeHTTP,
+			ExecuteAt: null.TimeFrom(executeAt),
+			HTTPJob:   &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+			Priority:  priority,
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create a job: %s", err)
+		}
+
+		return job
+	}
+
+	low := createJob(now.Add(time.Second), -10)
+	high := createJob(now.Add(2*time.Second), 10)
+
+	// The job with the higher priority is run first, even though it is due later
+	// -------------------------------------------------------------------------
+
+	at := now.Add(5 * time.Second)
+
+	jobs, err := jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance1", nil, 1)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].ID != high.ID || jobs[0].Priority != high.Priority {
+		t.Fatalf("Should get back the job with the higher priority: %v", jobs)
+	}
+
+	err = jobService.FinishJobExecution(ctx, jobs[0], at, at.Add(time.Second), nil)
+	if err != nil {
+		t.Fatalf("Should be able to finish job execution: %s", err)
+	}
+
+	// A job that waits long enough is run before a job with a higher priority that just became due
+	// -------------------------------------------------------------------------
+
+	at = now.Add(25 * model.PriorityAgingInterval)
+	newer := createJob(at.Add(-time.Second), 10)
+
+	jobs, err = jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance1", nil, 1)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].ID != low.ID {
+		t.Fatalf("Should get back the job that has been waiting for the longest time: %v, newer job %s", jobs, newer.ID)
+	}
+}
+
+func jobPool(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// Create jobs
+	// -------------------------------------------------------------------------
+
+	createJob := func(pool null.String) *model.Job {
+		job, err := jobService.CreateJob(ctx, &model.JobCreate{
+			Type:      model.JobTypeHTTP,
+			ExecuteAt: null.TimeFrom(now.Add(time.Second)),
+			HTTPJob:   &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+			Pool:      pool,
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create a job: %s", err)
+		}
+
+		return job
+	}
+
+	internal := createJob(null.StringFrom("internal"))
+	shared := createJob(null.String{})
+
+	// A runner outside of the pool only gets the job that is not routed to a pool
+	// -------------------------------------------------------------------------
+
+	at := now.Add(2 * time.Second)
+
+	jobs, err := jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].ID != shared.ID {
+		t.Fatalf("Should get back the job without a pool: %v", jobs)
+	}
+
+	// A runner in the pool gets the job routed to the pool
+	// -------------------------------------------------------------------------
+
+	jobs, err = jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance2", []string{"external", "internal"}, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].ID != internal.ID || jobs[0].Pool.String != "internal" {
+		t.Fatalf("Should get back the job routed to the pool: %v", jobs)
+	}
+}
+
+func jobTemplate(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	jobService := NewService(postgres.New(test.DB, test.Log), test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// A job with an invalid template is rejected
+	// -------------------------------------------------------------------------
+
+	jobCreate := &model.JobCreate{
+		Type:      model.JobTypeHTTP,
+		ExecuteAt: null.TimeFrom(now.Add(time.Second)),
+		HTTPJob: &model.HTTPJob{
+			URL:      "https://www.ardanlabs.com/{{ .JobID",
+			Method:   "GET",
+			Auth:     model.Auth{Type: model.AuthTypeNone},
+			Template: true,
+		},
+	}
+
+	if _, err := jobService.CreateJob(ctx, jobCreate); !errors.Is(err, model.ErrInvalidTemplate) {
+		t.Fatalf("Should not be able to create a job with an invalid template: %v", err)
+	}
+
+	// The run of a job knows when it was due
+	// -------------------------------------------------------------------------
+
+	jobCreate.HTTPJob.URL = "https://www.ardanlabs.com/{{ .JobID }}"
+
+	job, err := jobService.CreateJob(ctx, jobCreate)
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	at := now.Add(2 * time.Second)
+
+	jobs, err := jobService.GetJobsToRun(ctx, at, at.Add(5*time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || !jobs[0].ScheduledAt.Time.Equal(job.ExecuteAt.Time.Truncate(time.Microsecond)) {
+		t.Fatalf("Should get back the job scheduled at its execute at time: %v", jobs)
+	}
+
+	// The execution is recorded with its ID and response
+	// -------------------------------------------------------------------------
+
+	jobs[0].ExecutionID = uuid.New()
+	jobs[0].Response = &model.HTTPResponse{
+		StatusCode: 200,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       `{"ok": true}`,
+		Redirects:  []model.RedirectHop{{StatusCode: 302, Method: "GET", URL: "https://example.com/v2/hooks"}},
+	}
+
+	if err := jobService.FinishJobExecution(ctx, jobs[0], at, at.Add(time.Second), nil); err != nil {
+		t.Fatalf("Should be able to finish the job execution: %s", err)
+	}
+
+	executions, err := jobService.GetJobExecutions(ctx, job.ID, false, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to get job executions: %s", err)
+	}
+
+	if len(executions) != 1 || executions[0].ExecutionID.UUID != jobs[0].ExecutionID {
+		t.Fatalf("Should get back the execution with its ID: %v", executions)
+	}
+
+	if diff := cmp.Diff(jobs[0].Response, executions[0].Response); diff != "" {
+		t.Fatalf("Should get back the execution with its response:\n%s", diff)
+	}
+}
@@ -0,0 +1,98 @@
+package workflow
+
+import (
+	"context"
+	"github.com/GLCharge/otelzap"
+	"github.com/google/uuid"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/GLCharge/distributed-scheduler/store"
+)
+
+// Service is a struct that contains a store and a logger.
+type Service struct {
+	store store.Storer
+	log   *otelzap.Logger
+}
+
+// NewService creates a new workflow service with the given store and logger.
+func NewService(store store.Storer, log *otelzap.Logger) *Service {
+	return &Service{
+		store: store,
+		log:   log,
+	}
+}
+
+// CreateWorkflow creates a new workflow using the given workflow create request and returns the created workflow.
+// If the workflow create request is invalid, or a job of its nodes doesn't exist, an error is returned.
+func (s *Service) CreateWorkflow(ctx context.Context, workflowCreate *model.WorkflowCreate) (*model.Workflow, error) {
+
+	// Convert the workflow create request to a workflow
+	workflow := workflowCreate.ToWorkflow()
+
+	// Validate the workflow
+	if err := workflow.Validate(); err != nil {
+		return nil, err
+	}
+
+	// The nodes must run existing jobs
+	for _, node := range workflow.Nodes {
+		if _, err := s.store.GetJob(ctx, node.JobID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create the workflow using the store
+	if err := s.store.CreateWorkflow(ctx, workflow); err != nil {
+		return nil, err
+	}
+
+	return workflow, nil
+}
+
+// GetWorkflow returns the workflow with the given ID.
+func (s *Service) GetWorkflow(ctx context.Context, id uuid.UUID) (*model.Workflow, error) {
+	return s.store.GetWorkflow(ctx, id)
+}
+
+// ListWorkflows returns a list of workflows with the given limit and offset.
+func (s *Service) ListWorkflows(ctx context.Context, limit, offset uint64) ([]model.Workflow, error) {
+	return s.store.ListWorkflows(ctx, limit, offset)
+}
+
+// DeleteWorkflow deletes the workflow with the given ID together with its runs.
+func (s *Service) DeleteWorkflow(ctx context.Context, id uuid.UUID) error {
+	return s.store.DeleteWorkflow(ctx, id)
+}
+
+// TriggerWorkflow starts a new run of the workflow with the given ID and returns it. The jobs of the nodes
+// that don't depend on other nodes are triggered right away, the other nodes follow as their jobs finish.
+func (s *Service) TriggerWorkflow(ctx context.Context, id uuid.UUID) (*model.WorkflowRun, error) {
+	workflow, err := s.store.GetWorkflow(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	run := workflow.NewRun()
+
+	if err := s.store.CreateWorkflowRun(ctx, run, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
+
+// GetWorkflowRun returns the run with the given ID of the workflow with the given ID.
+func (s *Service) GetWorkflowRun(ctx context.Context, workflowID, runID uuid.UUID) (*model.WorkflowRun, error) {
+	return s.store.GetWorkflowRun(ctx, workflowID, runID)
+}
+
+// ListWorkflowRuns returns the runs of the workflow with the given ID, latest first, with the given limit and offset.
+func (s *Service) ListWorkflowRuns(ctx context.Context, workflowID uuid.UUID, limit, offset uint64) ([]model.WorkflowRun, error) {
+	if _, err := s.store.GetWorkflow(ctx, workflowID); err != nil {
+		return nil, err
+	}
+
+	return s.store.ListWorkflowRuns(ctx, workflowID, limit, offset)
+}
@@ -0,0 +1,397 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/GLCharge/distributed-scheduler/foundation/database/dbtest"
+	"github.com/GLCharge/distributed-scheduler/foundation/docker"
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/GLCharge/distributed-scheduler/service/job"
+	"github.com/GLCharge/distributed-scheduler/store/postgres"
+	"github.com/google/uuid"
+	"gopkg.in/guregu/null.v4"
+	"runtime/debug"
+	"testing"
+	"time"
+)
+
+var c *docker.Container
+
+func TestMain(m *testing.M) {
+	var err error
+	c, err = dbtest.StartDB()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer dbtest.StopDB(c)
+
+	m.Run()
+}
+
+func Test_Workflow(t *testing.T) {
+	t.Run("crud", crud)
+	t.Run("workflow_run", workflowRun)
+	t.Run("workflow_run_replaced", workflowRunReplaced)
+}
+
+func crud(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := postgres.New(test.DB, test.Log)
+	jobService := job.NewService(store, test.Log)
+	workflowService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	j, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:         model.JobTypeHTTP,
+		CronSchedule: null.StringFrom("0 1 * * *"),
+		HTTPJob:      &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	// Create workflow
+	// -------------------------------------------------------------------------
+
+	_, err = workflowService.CreateWorkflow(ctx, &model.WorkflowCreate{
+		Name:  "billing close",
+		Nodes: []model.WorkflowNode{{Name: "export", JobID: uuid.New()}},
+	})
+	if !errors.Is(err, model.ErrJobNotFound) {
+		t.Fatalf("Should not be able to create a workflow of a job that doesn't exist: %v", err)
+	}
+
+	workflow, err := workflowService.CreateWorkflow(ctx, &model.WorkflowCreate{
+		Name:  "billing close",
+		Nodes: []model.WorkflowNode{{Name: "export", JobID: j.ID}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a workflow: %s", err)
+	}
+
+	// Get and list workflows
+	// -------------------------------------------------------------------------
+
+	got, err := workflowService.GetWorkflow(ctx, workflow.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get the workflow: %s", err)
+	}
+
+	if got.Name != workflow.Name || len(got.Nodes) != 1 || got.Nodes[0].JobID != j.ID {
+		t.Fatalf("Should get back the same workflow: %+v", got)
+	}
+
+	workflows, err := workflowService.ListWorkflows(ctx, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to list workflows: %s", err)
+	}
+
+	if len(workflows) != 1 {
+		t.Fatalf("Should get back 1 workflow: %d", len(workflows))
+	}
+
+	// Delete workflow
+	// -------------------------------------------------------------------------
+
+	if err := workflowService.DeleteWorkflow(ctx, workflow.ID); err != nil {
+		t.Fatalf("Should be able to delete the workflow: %s", err)
+	}
+
+	if _, err := workflowService.GetWorkflow(ctx, workflow.ID); !errors.Is(err, model.ErrWorkflowNotFound) {
+		t.Fatalf("Should not be able to get a deleted workflow: %v", err)
+	}
+}
+
+func workflowRun(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := postgres.New(test.DB, test.Log)
+	jobService := job.NewService(store, test.Log)
+	workflowService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create jobs and the workflow
+	// -------------------------------------------------------------------------
+
+	createJob := func() *model.Job {
+		j, err := jobService.CreateJob(ctx, &model.JobCreate{
+			Type:         model.JobTypeHTTP,
+			CronSchedule: null.StringFrom("0 1 1 1 *"),
+			HTTPJob:      &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		})
+		if err != nil {
+			t.Fatalf("Should be able to create a job: %s", err)
+		}
+
+		return j
+	}
+
+	export, invoices, publish, alert := createJob(), createJob(), createJob(), createJob()
+
+	workflow, err := workflowService.CreateWorkflow(ctx, &model.WorkflowCreate{
+		Name: "billing close",
+		Nodes: []model.WorkflowNode{
+			{Name: "export", JobID: export.ID},
+			{Name: "invoices", JobID: invoices.ID, DependsOn: []model.WorkflowDependency{{Node: "export"}}},
+			{Name: "publish", JobID: publish.ID, DependsOn: []model.WorkflowDependency{{Node: "invoices"}}},
+			{Name: "alert", JobID: alert.ID, DependsOn: []model.WorkflowDependency{{Node: "invoices", Condition: model.WorkflowConditionFailure}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a workflow: %s", err)
+	}
+
+	// runNext runs the jobs that are due and fails the ones in failing
+	runNext := func(failing ...uuid.UUID) []*model.Job {
+		now := time.Now()
+
+		jobs, err := jobService.GetJobsToRun(ctx, now, now.Add(time.Minute), "instance1", nil, 10)
+		if err != nil {
+			t.Fatalf("Should be able to get jobs to run: %s", err)
+		}
+
+		for _, j := range jobs {
+			var runErr error
+			for _, id := range failing {
+				if j.ID == id {
+					runErr = &model.HTTPStatusError{StatusCode: 400}
+				}
+			}
+
+			if err := jobService.FinishJobExecution(ctx, j, now, now, runErr); err != nil {
+				t.Fatalf("Should be able to finish job execution: %s", err)
+			}
+		}
+
+		return jobs
+	}
+
+	// Trigger the workflow, only the first node is started
+	// -------------------------------------------------------------------------
+
+	run, err := workflowService.TriggerWorkflow(ctx, workflow.ID)
+	if err != nil {
+		t.Fatalf("Should be able to trigger the workflow: %s", err)
+	}
+
+	if run.Node(export.ID).Status != model.WorkflowNodeStatusRunning || run.Node(invoices.ID).Status != model.WorkflowNodeStatusPending {
+		t.Fatalf("Should start the first node only: %+v", run.Nodes)
+	}
+
+	jobs := runNext()
+	if len(jobs) != 1 || jobs[0].ID != export.ID || jobs[0].RunTrigger != model.RunTriggerWorkflow || jobs[0].WorkflowRunID.UUID != run.ID {
+		t.Fatalf("Should get back the job of the first node: %v", jobs)
+	}
+
+	// The downstream node runs once the first node succeeded, and fails
+	// -------------------------------------------------------------------------
+
+	jobs = runNext(invoices.ID)
+	if len(jobs) != 1 || jobs[0].ID != invoices.ID {
+		t.Fatalf("Should get back the job of the second node: %v", jobs)
+	}
+
+	// Only the node that handles the failure runs
+	// -------------------------------------------------------------------------
+
+	jobs = runNext()
+	if len(jobs) != 1 || jobs[0].ID != alert.ID {
+		t.Fatalf("Should get back the job of the failure handler: %v", jobs)
+	}
+
+	got, err := workflowService.GetWorkflowRun(ctx, workflow.ID, run.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get the workflow run: %s", err)
+	}
+
+	want := map[uuid.UUID]model.WorkflowNodeStatus{
+		export.ID:   model.WorkflowNodeStatusSucceeded,
+		invoices.ID: model.WorkflowNodeStatusFailed,
+		publish.ID:  model.WorkflowNodeStatusSkipped,
+		alert.ID:    model.WorkflowNodeStatusSucceeded,
+	}
+	for jobID, status := range want {
+		if got.Node(jobID).Status != status {
+			t.Fatalf("Should get back node status %s: %+v", status, got.Node(jobID))
+		}
+	}
+
+	if got.Status != model.WorkflowRunStatusFailed || !got.FinishedAt.Valid {
+		t.Fatalf("Should finish the workflow run as failed: %+v", got)
+	}
+
+	runs, err := workflowService.ListWorkflowRuns(ctx, workflow.ID, 10, 0)
+	if err != nil {
+		t.Fatalf("Should be able to list workflow runs: %s", err)
+	}
+
+	if len(runs) != 1 || runs[0].ID != run.ID {
+		t.Fatalf("Should get back the workflow run: %v", runs)
+	}
+
+	// A stale outcome of a node's job doesn't complete the node
+	// -------------------------------------------------------------------------
+
+	run, err = workflowService.TriggerWorkflow(ctx, workflow.ID)
+	if err != nil {
+		t.Fatalf("Should be able to trigger the workflow: %s", err)
+	}
+
+	now := time.Now()
+
+	staleJobs, err := jobService.GetJobsToRun(ctx, now, now.Add(time.Second), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	jobs, err = jobService.GetJobsToRun(ctx, now.Add(2*time.Second), now.Add(time.Minute), "instance2", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(staleJobs) != 1 || len(jobs) != 1 || jobs[0].ID != export.ID {
+		t.Fatalf("Should get back the job of the first node twice: %v %v", staleJobs, jobs)
+	}
+
+	err = jobService.FinishJobExecution(ctx, staleJobs[0], now, now, nil)
+	if !errors.Is(err, model.ErrStaleFencingToken) {
+		t.Fatalf("Should reject the stale outcome: %v", err)
+	}
+
+	got, err = workflowService.GetWorkflowRun(ctx, workflow.ID, run.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get the workflow run: %s", err)
+	}
+
+	if got.Node(export.ID).Status != model.WorkflowNodeStatusRunning {
+		t.Fatalf("Should leave the node running: %+v", got.Node(export.ID))
+	}
+
+	if err := jobService.FinishJobExecution(ctx, jobs[0], now, now.Add(2*time.Second), nil); err != nil {
+		t.Fatalf("Should be able to finish job execution: %s", err)
+	}
+
+	got, err = workflowService.GetWorkflowRun(ctx, workflow.ID, run.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get the workflow run: %s", err)
+	}
+
+	if got.Node(export.ID).Status != model.WorkflowNodeStatusSucceeded || got.Node(invoices.ID).Status != model.WorkflowNodeStatusRunning {
+		t.Fatalf("Should complete the node with the current claim's outcome: %+v", got.Nodes)
+	}
+}
+
+func workflowRunReplaced(t *testing.T) {
+	// Init
+	// -------------------------------------------------------------------------
+
+	test := dbtest.NewTest(t, c)
+	defer func() {
+		if r := recover(); r != nil {
+			t.Log(r)
+			t.Error(string(debug.Stack()))
+		}
+		test.Teardown()
+	}()
+
+	store := postgres.New(test.DB, test.Log)
+	jobService := job.NewService(store, test.Log)
+	workflowService := NewService(store, test.Log)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Create a job that replaces its runs and the workflow
+	// -------------------------------------------------------------------------
+
+	export, err := jobService.CreateJob(ctx, &model.JobCreate{
+		Type:              model.JobTypeHTTP,
+		CronSchedule:      null.StringFrom("*/5 * * * *"),
+		HTTPJob:           &model.HTTPJob{URL: "https://www.ardanlabs.com", Method: "GET", Auth: model.Auth{Type: model.AuthTypeNone}},
+		ConcurrencyPolicy: model.ConcurrencyPolicyReplace,
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a job: %s", err)
+	}
+
+	workflow, err := workflowService.CreateWorkflow(ctx, &model.WorkflowCreate{
+		Name:  "billing export",
+		Nodes: []model.WorkflowNode{{Name: "export", JobID: export.ID}},
+	})
+	if err != nil {
+		t.Fatalf("Should be able to create a workflow: %s", err)
+	}
+
+	// The workflow's run of the job is still in progress when its scheduled run is due
+	// -------------------------------------------------------------------------
+
+	run, err := workflowService.TriggerWorkflow(ctx, workflow.ID)
+	if err != nil {
+		t.Fatalf("Should be able to trigger the workflow: %s", err)
+	}
+
+	now := time.Now()
+
+	jobs, err := jobService.GetJobsToRun(ctx, now, now.Add(time.Hour), "instance1", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].WorkflowRunID.UUID != run.ID {
+		t.Fatalf("Should get back the job of the workflow run: %v", jobs)
+	}
+
+	at := export.NextRun.Time.Add(time.Second)
+
+	jobs, err = jobService.GetJobsToRun(ctx, at, at.Add(time.Hour), "instance2", nil, 10)
+	if err != nil {
+		t.Fatalf("Should be able to get jobs to run: %s", err)
+	}
+
+	if len(jobs) != 1 || jobs[0].RunTrigger != model.RunTriggerScheduled || jobs[0].WorkflowRunID.Valid {
+		t.Fatalf("Should get back the scheduled run that replaces the workflow's run: %v", jobs)
+	}
+
+	// The workflow run's node fails, so the workflow run is over
+	// -------------------------------------------------------------------------
+
+	got, err := workflowService.GetWorkflowRun(ctx, workflow.ID, run.ID)
+	if err != nil {
+		t.Fatalf("Should be able to get the workflow run: %s", err)
+	}
+
+	if got.Node(export.ID).Status != model.WorkflowNodeStatusFailed {
+		t.Fatalf("Should fail the node of the replaced run: %+v", got.Node(export.ID))
+	}
+
+	if got.Status != model.WorkflowRunStatusFailed || !got.FinishedAt.Valid {
+		t.Fatalf("Should finish the workflow run as failed: %+v", got)
+	}
+}
@@ -12,19 +12,42 @@ import (
 )
 
 type jobDB struct {
-	ID           uuid.UUID      `db:"id"`
-	Type         string         `db:"type"`
-	Status       string         `db:"status"`
-	ExecuteAt    null.Time      `db:"execute_at"`
-	CronSchedule null.String    `db:"cron_schedule"`
-	HTTPJob      []byte         `db:"http_job"`
-	AMQPJob      []byte         `db:"amqp_job"`
-	CreatedAt    time.Time      `db:"created_at"`
-	UpdatedAt    time.Time      `db:"updated_at"`
-	NextRun      null.Time      `db:"next_run"`
-	LockedUntil  null.Time      `db:"locked_until"`
-	LockedBy     null.String    `db:"locked_by"`
-	Tags         pq.StringArray `db:"tags"`
+	ID                     uuid.UUID      `db:"id"`
+	Type                   string         `db:"type"`
+	Status                 string         `db:"status"`
+	ExecuteAt              null.Time      `db:"execute_at"`
+	CronSchedule           null.String    `db:"cron_schedule"`
+	Timezone               null.String    `db:"timezone"`
+	HTTPJob                []byte         `db:"http_job"`
+	AMQPJob                []byte         `db:"amqp_job"`
+	GRPCJob                []byte         `db:"grpc_job"`
+	KafkaJob               []byte         `db:"kafka_job"`
+	MQTTJob                []byte         `db:"mqtt_job"`
+	NATSJob                []byte         `db:"nats_job"`
+	RedisJob               []byte         `db:"redis_job"`
+	RetryPolicy            []byte         `db:"retry_policy"`
+	TimeoutMs              null.Int       `db:"timeout_ms"`
+	MisfirePolicy          string         `db:"misfire_policy"`
+	MisfireThresholdMs     null.Int       `db:"misfire_threshold_ms"`
+	ConcurrencyPolicy      string         `db:"concurrency_policy"`
+	Priority               int            `db:"priority"`
+	Pool                   null.String    `db:"pool"`
+	WorkflowRunID          uuid.NullUUID  `db:"workflow_run_id"`
+	TriggeredWorkflowRunID uuid.NullUUID  `db:"triggered_workflow_run_id"`
+	CreatedAt              time.Time      `db:"created_at"`
+	UpdatedAt              time.Time      `db:"updated_at"`
+	NextRun                null.Time      `db:"next_run"`
+	LockedUntil            null.Time      `db:"locked_until"`
+	LockedBy               null.String    `db:"locked_by"`
+	ClaimedAt              null.Time      `db:"claimed_at"`
+	Tags                   pq.StringArray `db:"tags"`
+	RunID                  uuid.NullUUID  `db:"run_id"`
+	Attempt                int            `db:"attempt"`
+	RetryAt                null.Time      `db:"retry_at"`
+	FencingToken           int64          `db:"fencing_token"`
+	RunTrigger             string         `db:"run_trigger"`
+	TriggeredAt            null.Time      `db:"triggered_at"`
+	ScheduledAt            null.Time      `db:"scheduled_at"`
 }
 
 func toJobDB(j *model.Job) (*jobDB, error) {
@@ -34,10 +57,38 @@ func toJobDB(j *model.Job) (*jobDB, error) {
 		Status:       string(j.Status),
 		ExecuteAt:    j.ExecuteAt,
 		CronSchedule: j.CronSchedule,
+		Timezone:     j.Timezone,
 		CreatedAt:    j.CreatedAt,
 		UpdatedAt:    j.UpdatedAt,
 		NextRun:      j.NextRun,
 		Tags:         j.Tags,
+		RunID:        j.RunID,
+		Attempt:      j.Attempt,
+		RetryAt:      j.RetryAt,
+		FencingToken: j.FencingToken,
+		RunTrigger:   string(j.RunTrigger),
+		TriggeredAt:  j.TriggeredAt,
+		ScheduledAt:  j.ScheduledAt,
+		Priority:     j.Priority,
+		Pool:         j.Pool,
+
+		WorkflowRunID:          j.WorkflowRunID,
+		TriggeredWorkflowRunID: j.TriggeredWorkflowRunID,
+	}
+
+	if dbJ.RunTrigger == "" {
+		dbJ.RunTrigger = string(model.RunTriggerScheduled)
+	}
+
+	if j.Timeout != nil {
+		dbJ.TimeoutMs = null.IntFrom(j.Timeout.Duration().Milliseconds())
+	}
+
+	dbJ.MisfirePolicy = string(j.EffectiveMisfirePolicy())
+	dbJ.ConcurrencyPolicy = string(j.EffectiveConcurrencyPolicy())
+
+	if j.MisfireThreshold != nil {
+		dbJ.MisfireThresholdMs = null.IntFrom(j.MisfireThreshold.Duration().Milliseconds())
 	}
 
 	if j.HTTPJob != nil {
@@ -57,6 +108,54 @@ func toJobDB(j *model.Job) (*jobDB, error) {
 		dbJ.AMQPJob = amqpJob
 	}
 
+	if j.GRPCJob != nil {
+		grpcJob, err := json.Marshal(j.GRPCJob)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal grpc job")
+		}
+		dbJ.GRPCJob = grpcJob
+	}
+
+	if j.KafkaJob != nil {
+		kafkaJob, err := json.Marshal(j.KafkaJob)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal kafka job")
+		}
+		dbJ.KafkaJob = kafkaJob
+	}
+
+	if j.MQTTJob != nil {
+		mqttJob, err := json.Marshal(j.MQTTJob)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal mqtt job")
+		}
+		dbJ.MQTTJob = mqttJob
+	}
+
+	if j.NATSJob != nil {
+		natsJob, err := json.Marshal(j.NATSJob)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal nats job")
+		}
+		dbJ.NATSJob = natsJob
+	}
+
+	if j.RedisJob != nil {
+		redisJob, err := json.Marshal(j.RedisJob)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal redis job")
+		}
+		dbJ.RedisJob = redisJob
+	}
+
+	if j.RetryPolicy != nil {
+		retryPolicy, err := json.Marshal(j.RetryPolicy)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal retry policy")
+		}
+		dbJ.RetryPolicy = retryPolicy
+	}
+
 	return dbJ, nil
 }
 
@@ -67,10 +166,36 @@ func (j *jobDB) ToJob() (*model.Job, error) {
 		Status:       model.JobStatus(j.Status),
 		ExecuteAt:    j.ExecuteAt,
 		CronSchedule: j.CronSchedule,
+		Timezone:     j.Timezone,
 		CreatedAt:    j.CreatedAt,
 		UpdatedAt:    j.UpdatedAt,
 		NextRun:      j.NextRun,
 		Tags:         j.Tags,
+		RunID:        j.RunID,
+		Attempt:      j.Attempt,
+		RetryAt:      j.RetryAt,
+		FencingToken: j.FencingToken,
+		RunTrigger:   model.RunTrigger(j.RunTrigger),
+		TriggeredAt:  j.TriggeredAt,
+		ScheduledAt:  j.ScheduledAt,
+		Priority:     j.Priority,
+		Pool:         j.Pool,
+
+		WorkflowRunID:          j.WorkflowRunID,
+		TriggeredWorkflowRunID: j.TriggeredWorkflowRunID,
+	}
+
+	if j.TimeoutMs.Valid {
+		timeout := model.Duration(time.Duration(j.TimeoutMs.Int64) * time.Millisecond)
+		job.Timeout = &timeout
+	}
+
+	job.MisfirePolicy = model.MisfirePolicy(j.MisfirePolicy)
+	job.ConcurrencyPolicy = model.ConcurrencyPolicy(j.ConcurrencyPolicy)
+
+	if j.MisfireThresholdMs.Valid {
+		threshold := model.Duration(time.Duration(j.MisfireThresholdMs.Int64) * time.Millisecond)
+		job.MisfireThreshold = &threshold
 	}
 
 	if err := unmarshalNullableJSON(j.HTTPJob, &job.HTTPJob); err != nil {
@@ -81,6 +206,30 @@ func (j *jobDB) ToJob() (*model.Job, error) {
 		return nil, errors.Wrap(err, "failed to unmarshal amqp job")
 	}
 
+	if err := unmarshalNullableJSON(j.GRPCJob, &job.GRPCJob); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal grpc job")
+	}
+
+	if err := unmarshalNullableJSON(j.KafkaJob, &job.KafkaJob); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal kafka job")
+	}
+
+	if err := unmarshalNullableJSON(j.MQTTJob, &job.MQTTJob); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal mqtt job")
+	}
+
+	if err := unmarshalNullableJSON(j.NATSJob, &job.NATSJob); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal nats job")
+	}
+
+	if err := unmarshalNullableJSON(j.RedisJob, &job.RedisJob); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal redis job")
+	}
+
+	if err := unmarshalNullableJSON(j.RetryPolicy, &job.RetryPolicy); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal retry policy")
+	}
+
 	return job, nil
 }
 
@@ -92,22 +241,175 @@ func unmarshalNullableJSON(data []byte, v interface{}) error {
 }
 
 type executionDB struct {
-	ID           int         `db:"id"`
-	JobID        uuid.UUID   `db:"job_id"`
-	Status       string      `db:"status"`
-	StartTime    time.Time   `db:"start_time"`
-	EndTime      time.Time   `db:"end_time"`
-	ErrorMessage null.String `db:"error_message"`
-	CreatedAt    time.Time   `db:"created_at"`
+	ID           int           `db:"id"`
+	JobID        uuid.UUID     `db:"job_id"`
+	RunID        uuid.NullUUID `db:"run_id"`
+	ExecutionID  uuid.NullUUID `db:"execution_id"`
+	Attempt      int           `db:"attempt"`
+	Trigger      string        `db:"run_trigger"`
+	Status       string        `db:"status"`
+	StartTime    time.Time     `db:"start_time"`
+	EndTime      time.Time     `db:"end_time"`
+	ErrorMessage null.String   `db:"error_message"`
+	CreatedAt    time.Time     `db:"created_at"`
+
+	ResponseStatusCode    null.Int    `db:"response_status_code"`
+	ResponseHeaders       []byte      `db:"response_headers"`
+	ResponseBody          null.String `db:"response_body"`
+	ResponseBodyTruncated bool        `db:"response_body_truncated"`
+	ResponseRedirects     []byte      `db:"response_redirects"`
 }
 
-func (e *executionDB) ToModel() *model.JobExecution {
-	return &model.JobExecution{
+func (e *executionDB) ToModel() (*model.JobExecution, error) {
+	execution := &model.JobExecution{
 		ID:           e.ID,
 		JobID:        e.JobID,
+		RunID:        e.RunID,
+		ExecutionID:  e.ExecutionID,
+		Attempt:      e.Attempt,
+		Trigger:      model.RunTrigger(e.Trigger),
+		Status:       model.JobExecutionStatus(e.Status),
 		Success:      e.Status == string(model.JobExecutionStatusSuccessful),
 		StartTime:    e.StartTime,
 		EndTime:      e.EndTime,
 		ErrorMessage: e.ErrorMessage,
 	}
+
+	if e.ResponseStatusCode.Valid {
+		execution.Response = &model.HTTPResponse{
+			StatusCode:    int(e.ResponseStatusCode.Int64),
+			Body:          e.ResponseBody.String,
+			BodyTruncated: e.ResponseBodyTruncated,
+		}
+
+		if err := unmarshalNullableJSON(e.ResponseHeaders, &execution.Response.Headers); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal response headers")
+		}
+
+		if err := unmarshalNullableJSON(e.ResponseRedirects, &execution.Response.Redirects); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal response redirects")
+		}
+	}
+
+	return execution, nil
+}
+
+func toExecutionDB(e *model.JobExecution) (*executionDB, error) {
+	dbE := &executionDB{
+		ID:           e.ID,
+		JobID:        e.JobID,
+		RunID:        e.RunID,
+		ExecutionID:  e.ExecutionID,
+		Attempt:      e.Attempt,
+		Trigger:      string(e.Trigger),
+		Status:       string(e.Status),
+		StartTime:    e.StartTime,
+		EndTime:      e.EndTime,
+		ErrorMessage: e.ErrorMessage,
+	}
+
+	if e.Response != nil {
+		headers, err := json.Marshal(e.Response.Headers)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to marshal response headers")
+		}
+
+		dbE.ResponseStatusCode = null.IntFrom(int64(e.Response.StatusCode))
+		dbE.ResponseHeaders = headers
+		dbE.ResponseBody = null.StringFrom(e.Response.Body)
+		dbE.ResponseBodyTruncated = e.Response.BodyTruncated
+
+		if len(e.Response.Redirects) > 0 {
+			redirects, err := json.Marshal(e.Response.Redirects)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to marshal response redirects")
+			}
+
+			dbE.ResponseRedirects = redirects
+		}
+	}
+
+	return dbE, nil
+}
+
+type workflowDB struct {
+	ID        uuid.UUID `db:"id"`
+	Name      string    `db:"name"`
+	Nodes     []byte    `db:"nodes"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func toWorkflowDB(w *model.Workflow) (*workflowDB, error) {
+	nodes, err := json.Marshal(w.Nodes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal workflow nodes")
+	}
+
+	return &workflowDB{
+		ID:        w.ID,
+		Name:      w.Name,
+		Nodes:     nodes,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}, nil
+}
+
+func (w *workflowDB) ToModel() (*model.Workflow, error) {
+	workflow := &model.Workflow{
+		ID:        w.ID,
+		Name:      w.Name,
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+
+	if err := json.Unmarshal(w.Nodes, &workflow.Nodes); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal workflow nodes")
+	}
+
+	return workflow, nil
+}
+
+type workflowRunDB struct {
+	ID         uuid.UUID `db:"id"`
+	WorkflowID uuid.UUID `db:"workflow_id"`
+	Status     string    `db:"status"`
+	Nodes      []byte    `db:"nodes"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+	FinishedAt null.Time `db:"finished_at"`
+}
+
+func toWorkflowRunDB(r *model.WorkflowRun) (*workflowRunDB, error) {
+	nodes, err := json.Marshal(r.Nodes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal workflow run nodes")
+	}
+
+	return &workflowRunDB{
+		ID:         r.ID,
+		WorkflowID: r.WorkflowID,
+		Status:     string(r.Status),
+		Nodes:      nodes,
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+		FinishedAt: r.FinishedAt,
+	}, nil
+}
+
+func (r *workflowRunDB) ToModel() (*model.WorkflowRun, error) {
+	run := &model.WorkflowRun{
+		ID:         r.ID,
+		WorkflowID: r.WorkflowID,
+		Status:     model.WorkflowRunStatus(r.Status),
+		CreatedAt:  r.CreatedAt,
+		UpdatedAt:  r.UpdatedAt,
+		FinishedAt: r.FinishedAt,
+	}
+
+	if err := json.Unmarshal(r.Nodes, &run.Nodes); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal workflow run nodes")
+	}
+
+	return run, nil
 }
@@ -28,6 +28,8 @@ func New(db *sqlx.DB, log *otelzap.Logger) store.Storer {
 	}
 }
 
+// UpdateJob writes the definition of the job. The job's status is only changed by pausing or resuming it,
+// so an update made from a read before a pause or a resume doesn't undo it.
 func (s *pgStore) UpdateJob(ctx context.Context, job *model.Job) error {
 
 	dbJob, err := toJobDB(job)
@@ -42,8 +44,21 @@ func (s *pgStore) UpdateJob(ctx context.Context, job *model.Job) error {
 			 type = :type,
 			 execute_at = :execute_at,
 			 cron_schedule = :cron_schedule,
+			 timezone = :timezone,
 			 http_job = :http_job,
 			 amqp_job = :amqp_job,
+			 grpc_job = :grpc_job,
+			 kafka_job = :kafka_job,
+			 mqtt_job = :mqtt_job,
+			 nats_job = :nats_job,
+			 redis_job = :redis_job,
+			 retry_policy = :retry_policy,
+			 timeout_ms = :timeout_ms,
+			 misfire_policy = :misfire_policy,
+			 misfire_threshold_ms = :misfire_threshold_ms,
+			 concurrency_policy = :concurrency_policy,
+			 priority = :priority,
+			 pool = :pool,
 			 updated_at = :updated_at,
 			 next_run = :next_run
 		WHERE id = :id
@@ -57,11 +72,155 @@ func (s *pgStore) UpdateJob(ctx context.Context, job *model.Job) error {
 	return nil
 }
 
+// SetJobStatus changes the status and the next run of the job. The retry state of the job's
+// current run is cleared, so a paused job doesn't retry a run once it is resumed.
+func (s *pgStore) SetJobStatus(ctx context.Context, jobID uuid.UUID, status model.JobStatus, nextRun null.Time) error {
+
+	query := `
+		UPDATE jobs SET 
+		        status = $1, next_run = $2, 
+		        run_id = null, attempt = 0, retry_at = null, updated_at = now() 
+		WHERE id = $3
+	`
+	res, err := s.db.ExecContext(ctx, query, status, nextRun, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to set job status in database: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return model.ErrJobNotFound
+	}
+
+	return nil
+}
+
+// PauseJobsByTags pauses the jobs that have all the given tags in a single statement and returns all of them.
+// The retry state of the paused jobs' current runs is cleared, jobs that are already paused are left as they are.
+func (s *pgStore) PauseJobsByTags(ctx context.Context, tags []string) ([]model.Job, error) {
+
+	// the jobs table in the outer select is read before the update, so the paused jobs come from its result
+	query := `
+		WITH paused AS (
+			UPDATE jobs SET 
+			        status = 'STOPPED', 
+			        run_id = null, attempt = 0, retry_at = null, updated_at = now() 
+			WHERE tags @> $1 AND status = 'RUNNING'
+			RETURNING *
+		)
+		SELECT * FROM paused
+		UNION ALL
+		SELECT * FROM jobs WHERE tags @> $1 AND status <> 'RUNNING'
+		ORDER BY id DESC
+	`
+
+	var dbJobs []jobDB
+	err := s.db.SelectContext(ctx, &dbJobs, query, tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pause jobs in database: %w", err)
+	}
+
+	return toJobs(dbJobs)
+}
+
+// ResumeJobsByTags resumes the paused jobs that have all the given tags and returns all the jobs that have
+// the tags. The next run of a recurring job is computed from its cron schedule, a one-off job keeps its next
+// run. The paused jobs are locked while their next runs are computed, so they are resumed all at once.
+func (s *pgStore) ResumeJobsByTags(ctx context.Context, tags []string) ([]model.Job, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	var paused []jobDB
+	err = tx.SelectContext(ctx, &paused, `SELECT * FROM jobs WHERE tags @> $1 AND status = 'STOPPED' FOR UPDATE`, tags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get paused jobs from database: %w", err)
+	}
+
+	ids := make([]string, 0, len(paused))
+	nextRuns := make([]*time.Time, 0, len(paused))
+	for _, dbJob := range paused {
+		job, err := dbJob.ToJob()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
+		}
+
+		job.Resume()
+
+		ids = append(ids, job.ID.String())
+		nextRuns = append(nextRuns, job.NextRun.Ptr())
+	}
+
+	// the jobs table in the outer select is read before the update, so the resumed jobs come from its result
+	query := `
+		WITH resumed AS (
+			UPDATE jobs SET 
+			        status = 'RUNNING', next_run = resume.next_run, 
+			        run_id = null, attempt = 0, retry_at = null, updated_at = now() 
+			FROM unnest($2::uuid[], $3::timestamptz[]) AS resume(id, next_run)
+			WHERE jobs.id = resume.id
+			RETURNING jobs.*
+		)
+		SELECT * FROM resumed
+		UNION ALL
+		SELECT * FROM jobs WHERE tags @> $1 AND id NOT IN (SELECT id FROM resumed)
+		ORDER BY id DESC
+	`
+
+	var dbJobs []jobDB
+	err = tx.SelectContext(ctx, &dbJobs, query, tags, ids, nextRuns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume jobs in database: %w", err)
+	}
+
+	jobs, err := toJobs(dbJobs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return jobs, nil
+}
+
+// TriggerJob requests a manual run of the job at the given time. A manual run that is already pending is kept.
+func (s *pgStore) TriggerJob(ctx context.Context, jobID uuid.UUID, at time.Time) error {
+
+	query := `
+		UPDATE jobs SET triggered_at = COALESCE(triggered_at, $1), updated_at = now() 
+		WHERE id = $2
+	`
+	res, err := s.db.ExecContext(ctx, query, at, jobID)
+	if err != nil {
+		return fmt.Errorf("failed to trigger job in database: %w", err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return model.ErrJobNotFound
+	}
+
+	return nil
+}
+
 func (s *pgStore) GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool, limit, offset uint64) ([]*model.JobExecution, error) {
 
 	extraFilter := ""
 	if failedOnly {
-		extraFilter = " AND status = 'FAILED'"
+		extraFilter = " AND status IN ('FAILED', 'TIMED_OUT')"
 	}
 
 	query := `
@@ -83,7 +242,11 @@ func (s *pgStore) GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedO
 	// convert the JobExecutionDB struct to a JobExecution struct
 	var executions []*model.JobExecution
 	for _, dbExecution := range dbExecutions {
-		executions = append(executions, dbExecution.ToModel())
+		execution, err := dbExecution.ToModel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db job execution to job execution: %w", err)
+		}
+		executions = append(executions, execution)
 	}
 
 	return executions, nil
@@ -105,8 +268,21 @@ func (s *pgStore) CreateJob(ctx context.Context, job *model.Job) error {
 	 	status,
 	 	execute_at,
 	 	cron_schedule,
+	 	timezone,
 	 	http_job,
 	 	amqp_job,
+	 	grpc_job,
+	 	kafka_job,
+	 	mqtt_job,
+	 	nats_job,
+	 	redis_job,
+	 	retry_policy,
+	 	timeout_ms,
+	 	misfire_policy,
+	 	misfire_threshold_ms,
+	 	concurrency_policy,
+	 	priority,
+	 	pool,
 	 	created_at,
 	 	updated_at,
 	 	next_run,
@@ -117,8 +293,21 @@ func (s *pgStore) CreateJob(ctx context.Context, job *model.Job) error {
 	 	:status,
 	 	:execute_at,
 	 	:cron_schedule,
+	 	:timezone,
 	 	:http_job,
 	 	:amqp_job,
+	 	:grpc_job,
+	 	:kafka_job,
+	 	:mqtt_job,
+	 	:nats_job,
+	 	:redis_job,
+	 	:retry_policy,
+	 	:timeout_ms,
+	 	:misfire_policy,
+	 	:misfire_threshold_ms,
+	 	:concurrency_policy,
+	 	:priority,
+	 	:pool,
 	 	:created_at,
 	 	:updated_at,
 	 	:next_run,
@@ -191,7 +380,11 @@ func (s *pgStore) ListJobs(ctx context.Context, limit, offset uint64, tags []str
 		return nil, fmt.Errorf("failed to get jobs from database: %w", err)
 	}
 
-	// convert JobDB structs to Job structs
+	return toJobs(dbJobs)
+}
+
+// toJobs converts JobDB structs to Job structs.
+func toJobs(dbJobs []jobDB) ([]model.Job, error) {
 	jobs := []model.Job{}
 	for _, dbJob := range dbJobs {
 		job, err := dbJob.ToJob()
@@ -204,7 +397,7 @@ func (s *pgStore) ListJobs(ctx context.Context, limit, offset uint64, tags []str
 	return jobs, nil
 }
 
-func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error) {
+func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, pools []string, limit uint) ([]*model.Job, error) {
 	tx, err := s.db.Beginx()
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -212,14 +405,33 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 
 	defer rollback(tx, s.log)
 
-	// Get jobs that should be run at time at and are not currently locked
+	// Get jobs that should be run (or retried) at time at and are not currently locked.
+	// A manual run can be triggered for a paused job as well, but it waits for a pending retry to be made.
+	// A run whose lock expired before it was finished (its runner is gone) is picked up again as well.
+	// Recurring jobs that are still locked by their previous run are picked up when their next run becomes due
+	// (runs that were already due when the previous run was started, e.g. missed runs, wait for it to finish),
+	// so their concurrency policy can be applied. The claim clears the job's retry_at, so a job that is locked
+	// by a retry attempt is subject to its concurrency policy as well.
+	//
+	// Jobs with a higher priority are claimed first, then the ones that have been due for the longest time.
+	// The priority of a job grows by one for every aging interval it has been due, so jobs with a low priority
+	// are not starved by jobs with a higher priority that keep coming due.
+	//
+	// Jobs that are routed to a pool are only claimed by runners in that pool, other jobs by any runner.
 	rows, err := tx.QueryContext(ctx, `
 	   SELECT *
 	   FROM jobs
-	   WHERE next_run <= $1 AND (locked_until IS NULL OR locked_until <= $2) AND status = 'RUNNING'
-	   LIMIT $3
+	   WHERE (((locked_until IS NULL OR locked_until <= $1)
+	           AND ((COALESCE(retry_at, next_run) <= $1 AND status = 'RUNNING')
+	             OR (retry_at IS NULL AND triggered_at <= $1)
+	             OR (retry_at IS NULL AND run_id IS NOT NULL)))
+	      OR (locked_until > $1 AND retry_at IS NULL AND cron_schedule IS NOT NULL AND next_run <= $1 AND next_run > claimed_at AND status = 'RUNNING'))
+	     AND (pool IS NULL OR pool = ANY($4::text[]))
+	   ORDER BY priority + FLOOR(EXTRACT(EPOCH FROM $1 - LEAST(COALESCE(retry_at, next_run), triggered_at, locked_until)) / $3) DESC,
+	            LEAST(COALESCE(retry_at, next_run), triggered_at, locked_until)
+	   LIMIT $2
 	   FOR UPDATE SKIP LOCKED
-	`, at, at, limit)
+	`, at, limit, model.PriorityAgingInterval.Seconds(), pools)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query jobs: %w", err)
 	}
@@ -238,14 +450,101 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert db job to job: %w", err)
 		}
+
+		// the job's previous run is still in progress
+		leased := dbJob.LockedUntil.Valid && dbJob.LockedUntil.Time.After(at)
+
+		// A job whose previous run is in progress starts a new run, subject to its concurrency policy. Otherwise
+		// a job with a pending retry, or with a run that was left unfinished, makes the next attempt of its
+		// current run, and a new run is started if it has neither. A new run is a manual one if it was
+		// triggered, which takes precedence over a scheduled run that is due.
+		if leased || (!job.RetryAt.Valid && !job.RunID.Valid) {
+
+			// A scheduled run that is due while the previous run is in progress is subject to the job's
+			// concurrency policy, the Allow and Replace policies start the new run right away
+			if leased && job.EffectiveConcurrencyPolicy() == model.ConcurrencyPolicyForbid {
+				skipped := job.SkipDueRuns(at)
+
+				message := fmt.Sprintf("run skipped by concurrency policy %s, the previous run is still in progress", model.ConcurrencyPolicyForbid)
+				if err := insertSkippedExecutions(ctx, tx, job, skipped, model.JobExecutionStatusSkipped, message); err != nil {
+					return nil, err
+				}
+
+				if err := skipJobRuns(ctx, tx, job); err != nil {
+					return nil, err
+				}
+
+				continue
+			}
+
+			// The outcome of a run that is replaced, or overtaken, by the new run updates neither the job nor the
+			// workflow run that started it, so the workflow run's node fails rather than waiting for it forever
+			if leased && job.WorkflowRunID.Valid {
+				message := null.StringFrom(fmt.Sprintf("the job's run was overtaken by a new run under concurrency policy %s", job.EffectiveConcurrencyPolicy()))
+				if err := completeWorkflowNode(ctx, tx, job.WorkflowRunID.UUID, job.ID, message, at); err != nil {
+					return nil, err
+				}
+			}
+
+			job.RunID = uuid.NullUUID{UUID: uuid.New(), Valid: true}
+			job.Attempt = 0
+			job.RunTrigger = model.RunTriggerScheduled
+
+			job.WorkflowRunID = uuid.NullUUID{}
+
+			if !leased && job.TriggeredAt.Valid && !job.TriggeredAt.Time.After(at) {
+				job.RunTrigger = model.RunTriggerManual
+				job.ScheduledAt = job.TriggeredAt
+				job.TriggeredAt = null.Time{}
+
+				// the run belongs to the workflow run that triggered it
+				if job.TriggeredWorkflowRunID.Valid {
+					job.RunTrigger = model.RunTriggerWorkflow
+					job.WorkflowRunID = job.TriggeredWorkflowRunID
+					job.TriggeredWorkflowRunID = uuid.NullUUID{}
+				}
+			} else {
+				// A scheduled run that is picked up too late is subject to the job's misfire policy
+				missed, run := job.ApplyMisfirePolicy(at)
+
+				message := fmt.Sprintf("run skipped by misfire policy %s", job.EffectiveMisfirePolicy())
+				if err := insertSkippedExecutions(ctx, tx, job, missed, model.JobExecutionStatusMissed, message); err != nil {
+					return nil, err
+				}
+
+				if !run {
+					// the job is not run, it just waits for its next scheduled run
+					if err := skipJobRuns(ctx, tx, job); err != nil {
+						return nil, err
+					}
+
+					continue
+				}
+
+				// the run stands in for the (earliest) run that is due, a one-off job's run is due at its execute_at
+				job.ScheduledAt = job.NextRun
+
+				// the next run becomes due from now on, even if this run is still in progress by then
+				job.SetNextRunTime(at)
+			}
+		}
+		job.Attempt++
+
+		// the retry is made now
+		job.RetryAt = null.Time{}
+
 		jobs = append(jobs, job)
 
-		// Mark the job as locked by this instance
-		if _, err := tx.ExecContext(ctx, `
+		// Mark the job as locked by this instance, every claim gets a new fencing token
+		if err := tx.GetContext(ctx, &job.FencingToken, `
 	       UPDATE jobs
-	       SET locked_until = $1, locked_by = $2
-	       WHERE id = $3
-	   `, lockedUntil, instanceID, job.ID); err != nil {
+	       SET locked_until = $1, locked_by = $2, run_id = $3, attempt = $4, run_trigger = $5, triggered_at = $6,
+	           next_run = $7, claimed_at = $8, workflow_run_id = $9, triggered_workflow_run_id = $10, scheduled_at = $11,
+	           retry_at = null, fencing_token = fencing_token + 1
+	       WHERE id = $12
+	       RETURNING fencing_token
+	   `, lockedUntil, instanceID, job.RunID, job.Attempt, job.RunTrigger, job.TriggeredAt, job.NextRun, at,
+			job.WorkflowRunID, job.TriggeredWorkflowRunID, job.ScheduledAt, job.ID); err != nil {
 			return nil, fmt.Errorf("failed to lock job: %w", err)
 		}
 	}
@@ -257,33 +556,168 @@ func (s *pgStore) GetJobsToRun(ctx context.Context, at time.Time, lockedUntil ti
 	return jobs, nil
 }
 
-func (s *pgStore) FinishJob(ctx context.Context, jobID uuid.UUID, nextRun null.Time) error {
+// insertSkippedExecutions records the scheduled runs of the job that were skipped (by its misfire
+// or concurrency policy) with the given status and message.
+func insertSkippedExecutions(ctx context.Context, tx *sqlx.Tx, job *model.Job, skipped []time.Time, status model.JobExecutionStatus, message string) error {
+	if len(skipped) == 0 {
+		return nil
+	}
 
-	// finish job in database
+	query := `
+		INSERT INTO job_executions (job_id, attempt, run_trigger, start_time, end_time, status, error_message, created_at) 
+		SELECT $1::uuid, 0, 'scheduled'::run_trigger_enum, t, t, $3::job_execution_status_enum, $4::text, now()
+		FROM unnest($2::timestamptz[]) AS t
+	`
+
+	_, err := tx.ExecContext(ctx, query, job.ID, skipped, status, message)
+	if err != nil {
+		return fmt.Errorf("failed to record skipped job runs in database: %w", err)
+	}
+
+	return nil
+}
+
+// skipJobRuns moves the job's next run past the skipped runs, without claiming the job.
+func skipJobRuns(ctx context.Context, tx *sqlx.Tx, job *model.Job) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE jobs SET next_run = $1, updated_at = now() WHERE id = $2
+	`, job.NextRun, job.ID)
+	if err != nil {
+		return fmt.Errorf("failed to skip job runs: %w", err)
+	}
+
+	return nil
+}
+
+// RenewJobLocks extends the locks the instance holds on the given jobs, each with the fencing token of its claim,
+// and returns the IDs of the renewed jobs. Jobs that are missing from the result are no longer locked by the
+// instance, or were claimed again since, e.g. by a new run of a job that allows concurrent runs.
+func (s *pgStore) RenewJobLocks(ctx context.Context, locks map[uuid.UUID]int64, instanceID string, lockedUntil time.Time) ([]uuid.UUID, error) {
+
+	ids := make([]string, 0, len(locks))
+	fencingTokens := make([]int64, 0, len(locks))
+	for id, fencingToken := range locks {
+		ids = append(ids, id.String())
+		fencingTokens = append(fencingTokens, fencingToken)
+	}
+
+	query := `
+		UPDATE jobs SET locked_until = $1
+		FROM unnest($2::uuid[], $3::bigint[]) AS claim(id, fencing_token)
+		WHERE jobs.id = claim.id AND jobs.fencing_token = claim.fencing_token AND jobs.locked_by = $4
+		RETURNING jobs.id
+	`
+
+	var renewed []uuid.UUID
+	err := s.db.SelectContext(ctx, &renewed, query, lockedUntil, ids, fencingTokens, instanceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to renew job locks in database: %w", err)
+	}
+
+	return renewed, nil
+}
+
+// FinishJob ends the job's current run at time at (a failed run has an error message). A run started by
+// a workflow run records the outcome of the workflow run's node in the same transaction, so the node can't
+// be left running once the job is released. It returns model.ErrStaleFencingToken if the job was claimed
+// again after the job's claim.
+func (s *pgStore) FinishJob(ctx context.Context, job *model.Job, errorMessage null.String, at time.Time) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	// finish job in database (the run is over, so its retry state is cleared as well,
+	// the next run was already set when the run was started)
 	query := `
 		UPDATE jobs SET 
-		        next_run = $1, 
+		        run_id = null, attempt = 0, retry_at = null,
 		        locked_until = null, locked_by = null, updated_at = now() 
-		WHERE id = $2
+		WHERE id = $1 AND fencing_token = $2
 	`
-	_, err := s.db.ExecContext(ctx, query, nextRun, jobID)
+	res, err := tx.ExecContext(ctx, query, job.ID, job.FencingToken)
 	if err != nil {
 		return fmt.Errorf("failed to finish job in database: %w", err)
 	}
 
+	if err := checkFencedUpdate(res); err != nil {
+		return err
+	}
+
+	if job.WorkflowRunID.Valid {
+		if err := completeWorkflowNode(ctx, tx, job.WorkflowRunID.UUID, job.ID, errorMessage, at); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
-func (s *pgStore) CreateJobExecution(ctx context.Context, jobID uuid.UUID, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error {
 
-	// create job execution in database
+// ScheduleJobRetry releases the job until the retry is due. It returns model.ErrStaleFencingToken
+// if the job was claimed again after the claim with the given fencing token.
+func (s *pgStore) ScheduleJobRetry(ctx context.Context, jobID uuid.UUID, fencingToken int64, retryAt time.Time) error {
+
+	// release the job until the retry is due, so any runner can pick it up
+	query := `
+		UPDATE jobs SET 
+		        retry_at = $1, 
+		        locked_until = null, locked_by = null, updated_at = now() 
+		WHERE id = $2 AND fencing_token = $3
+	`
+	res, err := s.db.ExecContext(ctx, query, retryAt, jobID, fencingToken)
+	if err != nil {
+		return fmt.Errorf("failed to schedule job retry in database: %w", err)
+	}
+
+	return checkFencedUpdate(res)
+}
+
+// CreateJobExecution records the execution. It returns model.ErrStaleFencingToken if the job
+// was claimed again after the claim with the given fencing token, unless the job's concurrency
+// policy allows its runs to overlap and the claim was taken over by a new run. An execution of a
+// run that was claimed again, e.g. as its next attempt after the lock expired, is rejected.
+func (s *pgStore) CreateJobExecution(ctx context.Context, execution *model.JobExecution, fencingToken int64) error {
+
+	dbExecution, err := toExecutionDB(execution)
+	if err != nil {
+		return fmt.Errorf("failed to convert job execution to db job execution: %w", err)
+	}
+
+	// create job execution in database, only if the job's claim is still the one the execution was made with,
+	// or the job's current run (if any) is another one than the execution's and is allowed to overlap with it
 	query := `
-		INSERT INTO job_executions (job_id, start_time, end_time, status, error_message, created_at) 
-		VALUES ($1, $2, $3, $4, $5, now())
+		INSERT INTO job_executions (job_id, run_id, execution_id, attempt, run_trigger, start_time, end_time, status, error_message,
+		                            response_status_code, response_headers, response_body, response_body_truncated, response_redirects, created_at) 
+		SELECT id, $2::uuid, $10::uuid, $3::int, $4::run_trigger_enum, $5::timestamptz, $6::timestamptz, $7::job_execution_status_enum, $8::text,
+		       $11::int, $12::jsonb, $13::text, $14::boolean, $15::jsonb, now()
+		FROM jobs WHERE id = $1 AND (fencing_token = $9 OR (concurrency_policy = 'Allow' AND run_id IS DISTINCT FROM $2::uuid))
 	`
-	_, err := s.db.ExecContext(ctx, query, jobID, startTime, stopTime, status, errorMessage)
+	res, err := s.db.ExecContext(ctx, query, dbExecution.JobID, dbExecution.RunID, dbExecution.Attempt, dbExecution.Trigger, dbExecution.StartTime, dbExecution.EndTime,
+		dbExecution.Status, dbExecution.ErrorMessage, fencingToken, dbExecution.ExecutionID,
+		dbExecution.ResponseStatusCode, dbExecution.ResponseHeaders, dbExecution.ResponseBody, dbExecution.ResponseBodyTruncated, dbExecution.ResponseRedirects)
 	if err != nil {
 		return fmt.Errorf("failed to create job execution in database: %w", err)
 	}
 
+	return checkFencedUpdate(res)
+}
+
+// checkFencedUpdate returns model.ErrStaleFencingToken if a statement guarded by a fencing token didn't affect any row.
+func checkFencedUpdate(res sql.Result) error {
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return model.ErrStaleFencingToken
+	}
+
 	return nil
 }
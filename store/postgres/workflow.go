@@ -0,0 +1,241 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/GLCharge/distributed-scheduler/model"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/guregu/null.v4"
+)
+
+func (s *pgStore) CreateWorkflow(ctx context.Context, workflow *model.Workflow) error {
+
+	dbWorkflow, err := toWorkflowDB(workflow)
+	if err != nil {
+		return fmt.Errorf("failed to convert workflow to db workflow: %w", err)
+	}
+
+	query := `
+		INSERT INTO workflows (id, name, nodes, created_at, updated_at)
+		VALUES (:id, :name, :nodes, :created_at, :updated_at)
+	`
+
+	_, err = s.db.NamedExecContext(ctx, query, dbWorkflow)
+	if err != nil {
+		return fmt.Errorf("failed to insert workflow into database: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) GetWorkflow(ctx context.Context, id uuid.UUID) (*model.Workflow, error) {
+	var dbWorkflow workflowDB
+
+	err := s.db.GetContext(ctx, &dbWorkflow, `SELECT * FROM workflows WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrWorkflowNotFound
+		}
+		return nil, fmt.Errorf("failed to get workflow from database: %w", err)
+	}
+
+	workflow, err := dbWorkflow.ToModel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db workflow to workflow: %w", err)
+	}
+
+	return workflow, nil
+}
+
+func (s *pgStore) ListWorkflows(ctx context.Context, limit, offset uint64) ([]model.Workflow, error) {
+	var dbWorkflows []workflowDB
+
+	err := s.db.SelectContext(ctx, &dbWorkflows, `SELECT * FROM workflows ORDER BY created_at DESC LIMIT $1 OFFSET $2`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflows from database: %w", err)
+	}
+
+	workflows := []model.Workflow{}
+	for _, dbWorkflow := range dbWorkflows {
+		workflow, err := dbWorkflow.ToModel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db workflow to workflow: %w", err)
+		}
+		workflows = append(workflows, *workflow)
+	}
+
+	return workflows, nil
+}
+
+// DeleteWorkflow deletes the workflow together with its runs. Jobs that were already triggered by
+// the runs still run, but their outcome is no longer tracked.
+func (s *pgStore) DeleteWorkflow(ctx context.Context, id uuid.UUID) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM workflows WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete workflow from database: %w", err)
+	}
+
+	return nil
+}
+
+// CreateWorkflowRun starts the run of a workflow at time at: the jobs of the nodes that don't depend
+// on other nodes are triggered.
+func (s *pgStore) CreateWorkflowRun(ctx context.Context, run *model.WorkflowRun, at time.Time) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer rollback(tx, s.log)
+
+	if err := startWorkflowNodes(ctx, tx, run, run.Advance(at), at); err != nil {
+		return err
+	}
+
+	dbRun, err := toWorkflowRunDB(run)
+	if err != nil {
+		return fmt.Errorf("failed to convert workflow run to db workflow run: %w", err)
+	}
+
+	query := `
+		INSERT INTO workflow_runs (id, workflow_id, status, nodes, created_at, updated_at, finished_at)
+		VALUES (:id, :workflow_id, :status, :nodes, :created_at, :updated_at, :finished_at)
+	`
+
+	if _, err := tx.NamedExecContext(ctx, query, dbRun); err != nil {
+		return fmt.Errorf("failed to insert workflow run into database: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (s *pgStore) GetWorkflowRun(ctx context.Context, workflowID, runID uuid.UUID) (*model.WorkflowRun, error) {
+	var dbRun workflowRunDB
+
+	err := s.db.GetContext(ctx, &dbRun, `SELECT * FROM workflow_runs WHERE id = $1 AND workflow_id = $2`, runID, workflowID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, model.ErrWorkflowRunNotFound
+		}
+		return nil, fmt.Errorf("failed to get workflow run from database: %w", err)
+	}
+
+	run, err := dbRun.ToModel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert db workflow run to workflow run: %w", err)
+	}
+
+	return run, nil
+}
+
+func (s *pgStore) ListWorkflowRuns(ctx context.Context, workflowID uuid.UUID, limit, offset uint64) ([]model.WorkflowRun, error) {
+	var dbRuns []workflowRunDB
+
+	query := `
+		SELECT * FROM workflow_runs WHERE workflow_id = $1 ORDER BY created_at DESC LIMIT $2 OFFSET $3
+	`
+	err := s.db.SelectContext(ctx, &dbRuns, query, workflowID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get workflow runs from database: %w", err)
+	}
+
+	runs := []model.WorkflowRun{}
+	for _, dbRun := range dbRuns {
+		run, err := dbRun.ToModel()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert db workflow run to workflow run: %w", err)
+		}
+		runs = append(runs, *run)
+	}
+
+	return runs, nil
+}
+
+// completeWorkflowNode records the outcome of the job run of a workflow run's node at time at (a failed
+// run has an error message) and triggers the jobs of the nodes that can run next. The run is locked
+// meanwhile, so nodes that finish at the same time don't overwrite each other's outcome.
+func completeWorkflowNode(ctx context.Context, tx *sqlx.Tx, runID, jobID uuid.UUID, errorMessage null.String, at time.Time) error {
+	var dbRun workflowRunDB
+	err := tx.GetContext(ctx, &dbRun, `SELECT * FROM workflow_runs WHERE id = $1 FOR UPDATE`, runID)
+	if err != nil {
+		// the workflow may have been deleted in the meantime, the job's run is over all the same
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return fmt.Errorf("failed to get workflow run from database: %w", err)
+	}
+
+	run, err := dbRun.ToModel()
+	if err != nil {
+		return fmt.Errorf("failed to convert db workflow run to workflow run: %w", err)
+	}
+
+	// the outcome of a node is only recorded once
+	node := run.Node(jobID)
+	if node == nil || node.Status != model.WorkflowNodeStatusRunning {
+		return nil
+	}
+
+	if err := startWorkflowNodes(ctx, tx, run, run.CompleteNode(node, errorMessage, at), at); err != nil {
+		return err
+	}
+
+	updatedRun, err := toWorkflowRunDB(run)
+	if err != nil {
+		return fmt.Errorf("failed to convert workflow run to db workflow run: %w", err)
+	}
+
+	query := `
+		UPDATE workflow_runs SET status = :status, nodes = :nodes, updated_at = :updated_at, finished_at = :finished_at
+		WHERE id = :id
+	`
+	if _, err := tx.NamedExecContext(ctx, query, updatedRun); err != nil {
+		return fmt.Errorf("failed to update workflow run in database: %w", err)
+	}
+
+	return nil
+}
+
+// startWorkflowNodes triggers the jobs of the started nodes of the workflow run. A node whose job can't be
+// triggered, because it was deleted or it is already triggered by another workflow run, fails, which can
+// start or skip further nodes in turn.
+func startWorkflowNodes(ctx context.Context, tx *sqlx.Tx, run *model.WorkflowRun, started []*model.WorkflowRunNode, at time.Time) error {
+	query := `
+		UPDATE jobs SET triggered_at = COALESCE(triggered_at, $1), triggered_workflow_run_id = $2, updated_at = now()
+		WHERE id = $3 AND (triggered_workflow_run_id IS NULL OR triggered_workflow_run_id = $2)
+	`
+
+	for len(started) > 0 {
+		var next []*model.WorkflowRunNode
+
+		for _, node := range started {
+			res, err := tx.ExecContext(ctx, query, at, run.ID, node.JobID)
+			if err != nil {
+				return fmt.Errorf("failed to trigger workflow job in database: %w", err)
+			}
+
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get affected rows: %w", err)
+			}
+
+			if affected == 0 {
+				message := null.StringFrom("failed to trigger the job, it doesn't exist or it is already triggered by another workflow run")
+				next = append(next, run.CompleteNode(node, message, at)...)
+			}
+		}
+
+		started = next
+	}
+
+	return nil
+}
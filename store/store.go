@@ -16,10 +16,27 @@ type Storer interface {
 	DeleteJob(ctx context.Context, id uuid.UUID) error
 	ListJobs(ctx context.Context, limit, offset uint64, tags []string) ([]model.Job, error)
 	UpdateJob(ctx context.Context, job *model.Job) error
+	SetJobStatus(ctx context.Context, jobID uuid.UUID, status model.JobStatus, nextRun null.Time) error
+	PauseJobsByTags(ctx context.Context, tags []string) ([]model.Job, error)
+	ResumeJobsByTags(ctx context.Context, tags []string) ([]model.Job, error)
+	TriggerJob(ctx context.Context, jobID uuid.UUID, at time.Time) error
 
 	// Get jobs to run
-	GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, limit uint) ([]*model.Job, error)
-	FinishJob(ctx context.Context, jobID uuid.UUID, nextRun null.Time) error
-	CreateJobExecution(ctx context.Context, jobID uuid.UUID, startTime, stopTime time.Time, status model.JobExecutionStatus, errorMessage null.String) error
+	GetJobsToRun(ctx context.Context, at time.Time, lockedUntil time.Time, instanceID string, pools []string, limit uint) ([]*model.Job, error)
+	RenewJobLocks(ctx context.Context, locks map[uuid.UUID]int64, instanceID string, lockedUntil time.Time) ([]uuid.UUID, error)
+	FinishJob(ctx context.Context, job *model.Job, errorMessage null.String, at time.Time) error
+	ScheduleJobRetry(ctx context.Context, jobID uuid.UUID, fencingToken int64, retryAt time.Time) error
+	CreateJobExecution(ctx context.Context, execution *model.JobExecution, fencingToken int64) error
 	GetJobExecutions(ctx context.Context, jobID uuid.UUID, failedOnly bool, limit, offset uint64) ([]*model.JobExecution, error)
+
+	// CRUD operations for workflows
+	CreateWorkflow(ctx context.Context, workflow *model.Workflow) error
+	GetWorkflow(ctx context.Context, id uuid.UUID) (*model.Workflow, error)
+	ListWorkflows(ctx context.Context, limit, offset uint64) ([]model.Workflow, error)
+	DeleteWorkflow(ctx context.Context, id uuid.UUID) error
+
+	// Workflow runs
+	CreateWorkflowRun(ctx context.Context, run *model.WorkflowRun, at time.Time) error
+	GetWorkflowRun(ctx context.Context, workflowID, runID uuid.UUID) (*model.WorkflowRun, error)
+	ListWorkflowRuns(ctx context.Context, workflowID uuid.UUID, limit, offset uint64) ([]model.WorkflowRun, error)
 }